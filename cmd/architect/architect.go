@@ -6,12 +6,14 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/skatteetaten/architect/pkg/config"
 	"github.com/skatteetaten/architect/pkg/docker"
-	"github.com/skatteetaten/architect/pkg/doozer"
-	"github.com/skatteetaten/architect/pkg/java"
+	// The application types register their builds
+	_ "github.com/skatteetaten/architect/pkg/doozer"
+	_ "github.com/skatteetaten/architect/pkg/java"
 	"github.com/skatteetaten/architect/pkg/nexus"
-	"github.com/skatteetaten/architect/pkg/nodejs/prepare"
+	_ "github.com/skatteetaten/architect/pkg/nodejs/prepare"
 	"github.com/skatteetaten/architect/pkg/process/build"
 	"github.com/skatteetaten/architect/pkg/process/retag"
+	_ "github.com/skatteetaten/architect/pkg/python"
 	"os"
 	"strings"
 	"time"
@@ -88,17 +90,20 @@ func RunArchitect(configuration RunConfiguration) {
 	logrus.Infof("Timer stage=RunArchitect apptype=%s registry=%s repository=%s timetaken=%.3fs", c.ApplicationType, c.DockerSpec.OutputRegistry, c.DockerSpec.OutputRepository, time.Since(startTimer).Seconds())
 }
 func performBuild(ctx context.Context, configuration *RunConfiguration, c *config.Config, r *docker.RegistryCredentials, provider docker.ImageInfoProvider, builder process.Builder) error {
-	var prepper process.Prepper
-	if c.ApplicationType == config.JavaLeveransepakke {
-		logrus.Info("Perform Java build")
-		prepper = java.Prepper()
-	} else if c.ApplicationType == config.NodeJsLeveransepakke {
-		logrus.Info("Perform Webleveranse build")
-		prepper = prepare.Prepper()
-	} else if c.ApplicationType == config.DoozerLeveranse {
-		logrus.Info("Perform Doozerleveranse build")
-		prepper = doozer.Prepper()
+	applicationType, err := process.FindApplicationType(c.ApplicationType)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Perform %s build", applicationType.Name)
+	artifactDownloader := configuration.NexusDownloader
+	if c.BinaryBuild {
+		// The binary downloader only knows the deliverable. Without a Nexus nothing else can be downloaded
+		artifactDownloader = nil
+		if c.NexusAccess.NexusUrl != "" {
+			artifactDownloader = nexus.NewNexusDownloader(c.NexusAccess.NexusUrl)
+		}
 	}
+	prepper := applicationType.Prepper(artifactDownloader)
 
 	if !c.LocalBuild {
 		if c.BinaryBuild && !c.ApplicationSpec.MavenGav.IsSnapshot() {
@@ -114,7 +119,7 @@ func performBuild(ctx context.Context, configuration *RunConfiguration, c *confi
 		buildah := &process.BuildahCmd{
 			TlsVerify: c.TlsVerify,
 		}
-		return process.Build(ctx, r, provider, c, configuration.NexusDownloader, prepper, buildah)
+		return process.Build(ctx, r, provider, c, configuration.NexusDownloader, applicationType, prepper, buildah)
 
 	} else {
 		if !strings.Contains(c.BuildStrategy, config.Docker) {
@@ -127,6 +132,6 @@ func performBuild(ctx context.Context, configuration *RunConfiguration, c *confi
 		}
 
 		logrus.Info("Running docker build")
-		return process.Build(ctx, r, provider, c, configuration.NexusDownloader, prepper, dockerClient)
+		return process.Build(ctx, r, provider, c, configuration.NexusDownloader, applicationType, prepper, dockerClient)
 	}
 }
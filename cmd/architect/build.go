@@ -5,25 +5,28 @@ import (
 	"github.com/skatteetaten/architect/pkg/config"
 	"github.com/skatteetaten/architect/pkg/docker"
 	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/skatteetaten/architect/pkg/process/build"
 	"github.com/skatteetaten/architect/pkg/util"
 	"github.com/spf13/cobra"
+	"strings"
 )
 
 var noPush bool
 
 func init() {
 	Build.Flags().StringP("file", "f", "", "Path to the compressed leveransepakke")
-	Build.Flags().StringP("type", "t", "java", "Application type [java, doozer, nodejs]")
+	Build.Flags().StringP("type", "t", "java", "Application type ["+strings.Join(process.SupportedApplicationTypes(), ", ")+"]")
 	Build.Flags().StringP("output", "o", "", "Output repository with tag e.g aurora/architect:latest")
 	Build.Flags().StringP("from", "", "", "Base image e.g aurora/wingnut11:latest")
 	Build.Flags().StringP("push-registry", "", "container-registry-internal.aurora.skead.no", "Push registry")
 	Build.Flags().StringP("pull-registry", "", "container-registry-internal-private-pull.aurora.skead.no", "Pull registry")
+	Build.Flags().StringP("version-check", "", "warn", "When the version in the deliverable differs from the output tag [off, warn, fail]")
 	Build.Flags().BoolVarP(&noPush, "no-push", "", false, "If true the image is not pushed")
 	Build.Flags().BoolVarP(&verbose, "verbose", "v", false, "Verbose logging")
 }
 
 var Build = &cobra.Command{
-	Use:   "build --file <file> --from <baseimage:version> --output <repository:tag> --type [java | nodejs | doozer] ",
+	Use:   "build --file <file> --from <baseimage:version> --output <repository:tag> --type <application type>",
 	Short: "Build Docker image from binary source",
 	Run: func(cmd *cobra.Command, args []string) {
 
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+)
+
+// ApplicationTypeSpec is how the build variables of an application type are read. It is registered together with
+// the build of the type by process.RegisterApplicationType
+type ApplicationTypeSpec struct {
+	Type ApplicationType
+	// The values of APPLICATION_TYPE and --type, case insensitive. The first name is the one listed in the help
+	Names []string
+	// The classifier of an archive deliverable
+	Classifier Classifier
+	// The packaging when PACKAGING is not set
+	Packaging PackageType
+	// The packagings deployed with the classifier. Other packagings are single files without classifier
+	Archives []PackageType
+	// Optional. Validates PACKAGING. PACKAGING is ignored for types without it
+	ParsePackaging func(value string) (PackageType, error)
+}
+
+// Filled by process.RegisterApplicationType from the package of each application type
+var applicationTypes []ApplicationTypeSpec
+
+// RegisterApplicationType adds an application type, or replaces the type with the same name
+func RegisterApplicationType(spec ApplicationTypeSpec) {
+	for i, existing := range applicationTypes {
+		if existing.Type == spec.Type {
+			applicationTypes[i] = spec
+			return
+		}
+	}
+	applicationTypes = append(applicationTypes, spec)
+}
+
+// FindApplicationType finds the application type with the given name or alias
+func FindApplicationType(name string) (ApplicationTypeSpec, error) {
+	for _, spec := range applicationTypes {
+		for _, alias := range spec.Names {
+			if strings.EqualFold(alias, name) {
+				return spec, nil
+			}
+		}
+	}
+	return ApplicationTypeSpec{}, errors.Errorf("Unknown application type %s. Supported types are [%s]", name,
+		strings.Join(ApplicationTypeNames(), ", "))
+}
+
+// ApplicationTypeSpecFor returns the spec of a registered application type
+func ApplicationTypeSpecFor(applicationType ApplicationType) (ApplicationTypeSpec, error) {
+	for _, spec := range applicationTypes {
+		if spec.Type == applicationType {
+			return spec, nil
+		}
+	}
+	return ApplicationTypeSpec{}, errors.Errorf("Unknown application type %s", applicationType)
+}
+
+// ApplicationTypeNames returns the first name of each application type
+func ApplicationTypeNames() []string {
+	names := make([]string, 0, len(applicationTypes))
+	for _, spec := range applicationTypes {
+		names = append(names, spec.Names[0])
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsArchive tells if the packaging is deployed with the classifier of the application type
+func (m ApplicationTypeSpec) IsArchive(packaging PackageType) bool {
+	for _, archive := range m.Archives {
+		if archive == packaging {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadPackaging validates PACKAGING, or returns the default packaging when it is not set
+func (m ApplicationTypeSpec) ReadPackaging(value string, isSet bool) (PackageType, error) {
+	if !isSet || m.ParsePackaging == nil {
+		return m.Packaging, nil
+	}
+	return m.ParsePackaging(value)
+}
+
+// DefaultClassifier is the classifier of the packaging when CLASSIFIER is not set
+func (m ApplicationTypeSpec) DefaultClassifier(packaging PackageType) Classifier {
+	if m.IsArchive(packaging) {
+		return m.Classifier
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"github.com/skatteetaten/architect/pkg/config"
+	// The application types register themselves, like in the architect command
+	_ "github.com/skatteetaten/architect/pkg/doozer"
+	_ "github.com/skatteetaten/architect/pkg/java"
+	_ "github.com/skatteetaten/architect/pkg/nodejs/prepare"
+	_ "github.com/skatteetaten/architect/pkg/python"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindApplicationType(t *testing.T) {
+	spec, err := config.FindApplicationType("NODEJS")
+	assert.NoError(t, err)
+	assert.Equal(t, config.NodeJsLeveransepakke, spec.Type)
+
+	spec, err = config.FindApplicationType("doozer")
+	assert.NoError(t, err)
+	assert.Equal(t, config.DoozerLeveranse, spec.Type)
+
+	_, err = config.FindApplicationType("cobol")
+	assert.Contains(t, err.Error(), "Unknown application type cobol. Supported types are [doozer, java, nodejs")
+}
+
+func TestDefaultClassifier(t *testing.T) {
+	spec, err := config.ApplicationTypeSpecFor(config.DoozerLeveranse)
+	assert.NoError(t, err)
+	assert.Equal(t, config.Doozerleveransepakke, spec.DefaultClassifier(config.ZipPackaging))
+	assert.Equal(t, config.Doozerleveransepakke, spec.DefaultClassifier(config.TgzPackaging))
+	assert.Equal(t, config.Classifier(""), spec.DefaultClassifier("bin"))
+}
+
+func TestReadPackaging(t *testing.T) {
+	nodejs, err := config.ApplicationTypeSpecFor(config.NodeJsLeveransepakke)
+	assert.NoError(t, err)
+	packaging, err := nodejs.ReadPackaging("zip", true)
+	assert.NoError(t, err)
+	assert.Equal(t, config.TgzPackaging, packaging)
+
+	java, err := config.ApplicationTypeSpecFor(config.JavaLeveransepakke)
+	assert.NoError(t, err)
+	packaging, err = java.ReadPackaging("", false)
+	assert.NoError(t, err)
+	assert.Equal(t, config.ZipPackaging, packaging)
+	_, err = java.ReadPackaging("tgz", true)
+	assert.Error(t, err)
+}
+
+func TestRegisterApplicationType(t *testing.T) {
+	config.RegisterApplicationType(config.ApplicationTypeSpec{
+		Type:       "GoLeveranse",
+		Names:      []string{"go", "golang"},
+		Classifier: "Goleveransepakke",
+		Packaging:  config.TgzPackaging,
+		Archives:   []config.PackageType{config.TgzPackaging},
+	})
+
+	spec, err := config.FindApplicationType("golang")
+	assert.NoError(t, err)
+	assert.Equal(t, config.ApplicationType("GoLeveranse"), spec.Type)
+	assert.Equal(t, config.Classifier("Goleveransepakke"), spec.DefaultClassifier(config.TgzPackaging))
+	assert.Contains(t, config.ApplicationTypeNames(), "go")
+}
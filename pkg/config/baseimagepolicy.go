@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/json"
+	"github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"io"
+)
+
+// Where the base image of a build was chosen
+const (
+	BaseImageFromBuildConfig = "buildconfig"
+	BaseImageFromDeliverable = "deliverable"
+)
+
+/*
+BaseImagePolicy is owned by the platform. It lists the base images application teams are allowed to choose in
+the metadata of the deliverable. Without a policy the base image in the BuildConfig is always used.
+
+The policy is baseImagePolicy in the platform configuration:
+
+	{"baseImages": [{"name": "aurora/wingnut11", "versions": ">= 1, < 3"}]}
+*/
+type BaseImagePolicy struct {
+	BaseImages []AllowedBaseImage `json:"baseImages"`
+}
+
+type AllowedBaseImage struct {
+	Name string `json:"name"`
+	// Version constraint like ">= 1.2, < 2". All versions are allowed when empty
+	Versions string `json:"versions"`
+}
+
+func NewBaseImagePolicy(reader io.Reader) (*BaseImagePolicy, error) {
+	policy := &BaseImagePolicy{}
+	if err := json.NewDecoder(reader).Decode(policy); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal base image policy")
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (m *BaseImagePolicy) validate() error {
+	for _, allowed := range m.BaseImages {
+		if allowed.Name == "" {
+			return errors.New("Base image policy contains a base image without name")
+		}
+		if allowed.Versions != "" {
+			if _, err := version.NewConstraint(allowed.Versions); err != nil {
+				return errors.Wrapf(err, "Illegal versions %s for %s in base image policy", allowed.Versions, allowed.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Allows returns an error telling why the base image is not allowed
+func (m *BaseImagePolicy) Allows(spec DockerBaseImageSpec) error {
+	for _, allowed := range m.BaseImages {
+		if allowed.Name != spec.BaseImage {
+			continue
+		}
+		if allowed.Versions == "" {
+			return nil
+		}
+		constraint, err := version.NewConstraint(allowed.Versions)
+		if err != nil {
+			return errors.Wrapf(err, "Illegal versions %s for %s in base image policy", allowed.Versions, allowed.Name)
+		}
+		baseVersion, err := version.NewVersion(spec.BaseVersion)
+		if err != nil || !constraint.Check(baseVersion) {
+			return errors.Errorf("Base image %s:%s is not allowed by the base image policy. Allowed versions are %s",
+				spec.BaseImage, spec.BaseVersion, allowed.Versions)
+		}
+		return nil
+	}
+	return errors.Errorf("Base image %s is not allowed by the base image policy", spec.BaseImage)
+}
+
+/*
+SelectBaseImage decides which base image to build on. The precedence is:
+
+1. The deliverable does not declare a base image: the BuildConfig is used.
+2. There is no policy: the BuildConfig is used, and the base image in the deliverable is ignored.
+3. The deliverable declares a base image allowed by the policy: the deliverable is used. If the deliverable only
+declares baseVersion, the base image from the BuildConfig is used with that version.
+4. The deliverable declares a base image not allowed by the policy: the build fails.
+
+It returns the chosen base image, and where it came from.
+*/
+func SelectBaseImage(buildConfig DockerBaseImageSpec, deliverable DockerBaseImageSpec, policy *BaseImagePolicy) (DockerBaseImageSpec, string, error) {
+	if deliverable.BaseImage == "" && deliverable.BaseVersion == "" {
+		return buildConfig, BaseImageFromBuildConfig, nil
+	}
+	if policy == nil {
+		logrus.Warnf("The deliverable declares base image %s:%s, but there is no base image policy. Using %s:%s from the BuildConfig",
+			deliverable.BaseImage, deliverable.BaseVersion, buildConfig.BaseImage, buildConfig.BaseVersion)
+		return buildConfig, BaseImageFromBuildConfig, nil
+	}
+
+	selected := deliverable
+	if selected.BaseImage == "" {
+		selected.BaseImage = buildConfig.BaseImage
+	}
+	if selected.BaseVersion == "" {
+		if selected.BaseImage != buildConfig.BaseImage {
+			return DockerBaseImageSpec{}, "", errors.Errorf("The deliverable declares base image %s without baseVersion", selected.BaseImage)
+		}
+		selected.BaseVersion = buildConfig.BaseVersion
+	}
+
+	if err := policy.Allows(selected); err != nil {
+		return DockerBaseImageSpec{}, "", err
+	}
+	if selected != buildConfig {
+		logrus.Infof("The deliverable overrides base image %s:%s from the BuildConfig", buildConfig.BaseImage, buildConfig.BaseVersion)
+	}
+	return selected, BaseImageFromDeliverable, nil
+}
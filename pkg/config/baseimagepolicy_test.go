@@ -0,0 +1,59 @@
+package config_test
+
+import (
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const policyJson = `{"baseImages": [
+	{"name": "aurora/wingnut11", "versions": ">= 1, < 3"},
+	{"name": "aurora/wingnut17"}
+]}`
+
+var buildConfigBaseImage = config.DockerBaseImageSpec{BaseImage: "aurora/wingnut8", BaseVersion: "2"}
+
+func TestBaseImagePolicy(t *testing.T) {
+	policy, err := config.NewBaseImagePolicy(strings.NewReader(policyJson))
+	assert.NoError(t, err)
+
+	assert.NoError(t, policy.Allows(config.DockerBaseImageSpec{BaseImage: "aurora/wingnut11", BaseVersion: "2.1.0"}))
+	assert.NoError(t, policy.Allows(config.DockerBaseImageSpec{BaseImage: "aurora/wingnut17", BaseVersion: "latest"}))
+	assert.EqualError(t, policy.Allows(config.DockerBaseImageSpec{BaseImage: "aurora/wingnut11", BaseVersion: "3"}),
+		"Base image aurora/wingnut11:3 is not allowed by the base image policy. Allowed versions are >= 1, < 3")
+	assert.EqualError(t, policy.Allows(config.DockerBaseImageSpec{BaseImage: "aurora/wingnut11", BaseVersion: "latest"}),
+		"Base image aurora/wingnut11:latest is not allowed by the base image policy. Allowed versions are >= 1, < 3")
+	assert.EqualError(t, policy.Allows(config.DockerBaseImageSpec{BaseImage: "evil/image", BaseVersion: "1"}),
+		"Base image evil/image is not allowed by the base image policy")
+
+	_, err = config.NewBaseImagePolicy(strings.NewReader(`{"baseImages": [{"name": "aurora/wingnut11", "versions": "newest"}]}`))
+	assert.Error(t, err)
+}
+
+func TestSelectBaseImage(t *testing.T) {
+	policy, err := config.NewBaseImagePolicy(strings.NewReader(policyJson))
+	assert.NoError(t, err)
+
+	selected, source, err := config.SelectBaseImage(buildConfigBaseImage, config.DockerBaseImageSpec{}, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, buildConfigBaseImage, selected)
+	assert.Equal(t, config.BaseImageFromBuildConfig, source)
+
+	declared := config.DockerBaseImageSpec{BaseImage: "aurora/wingnut11", BaseVersion: "1"}
+	selected, source, err = config.SelectBaseImage(buildConfigBaseImage, declared, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, buildConfigBaseImage, selected)
+	assert.Equal(t, config.BaseImageFromBuildConfig, source)
+
+	selected, source, err = config.SelectBaseImage(buildConfigBaseImage, declared, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, declared, selected)
+	assert.Equal(t, config.BaseImageFromDeliverable, source)
+
+	_, _, err = config.SelectBaseImage(buildConfigBaseImage, config.DockerBaseImageSpec{BaseImage: "aurora/wingnut17"}, policy)
+	assert.EqualError(t, err, "The deliverable declares base image aurora/wingnut17 without baseVersion")
+
+	_, _, err = config.SelectBaseImage(buildConfigBaseImage, config.DockerBaseImageSpec{BaseVersion: "3"}, policy)
+	assert.EqualError(t, err, "Base image aurora/wingnut8 is not allowed by the base image policy")
+}
@@ -38,6 +38,9 @@ type CmdConfigReader struct {
 
 const FallbackDockerRegistry = "https://docker-registry.aurora.sits.no:5000"
 
+// Where the directory of PEM files is mounted when CA_CERTIFICATES_DIR is not set
+const DefaultCaCertificatesDir = "/u01/ca-certificates"
+
 func NewInClusterConfigReader() ConfigReader {
 	return &InClusterConfigReader{}
 }
@@ -56,18 +59,7 @@ func NewCmdConfigReader(cmd *cobra.Command, args []string, noPush bool) ConfigRe
 
 func (m *CmdConfigReader) ReadConfig() (*Config, error) {
 
-	var applicationType = JavaLeveransepakke
-
-	if len(m.Cmd.Flag("type").Value.String()) != 0 {
-		value := m.Cmd.Flag("type").Value.String()
-		if strings.ToLower(value) == "java" {
-			applicationType = JavaLeveransepakke
-		} else if strings.ToLower(value) == "nodejs" {
-			applicationType = NodeJsLeveransepakke
-		} else if strings.ToLower(value) == "doozer" {
-			applicationType = DoozerLeveranse
-		}
-	}
+	applicationType := readApplicationType(m.Cmd.Flag("type").Value.String()).Type
 
 	fromraw := m.Cmd.Flag("from").Value.String()
 	from := strings.Split(fromraw, ":")
@@ -81,6 +73,15 @@ func (m *CmdConfigReader) ReadConfig() (*Config, error) {
 		return nil, errors.New("--output: repository is malformed: " + outputraw)
 	}
 
+	versionCheck := VersionCheckWarn
+	if flag := m.Cmd.Flag("version-check"); flag != nil && flag.Value.String() != "" {
+		var err error
+		versionCheck, err = parseVersionCheck(flag.Value.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	pushRegistry := m.Cmd.Flag("push-registry").Value.String()
 	pullRegistry := m.Cmd.Flag("pull-registry").Value.String()
 
@@ -104,8 +105,11 @@ func (m *CmdConfigReader) ReadConfig() (*Config, error) {
 			OutputRegistry:       pushRegistry,
 			OutputRepository:     output[0],
 			TagWith:              output[1],
+			TemplateDir:          os.Getenv("TEMPLATE_DIR"),
+			AllowRootUser:        strings.ToLower(os.Getenv("ALLOW_ROOT_USER")) == "true",
 		},
 		BuildTimeout: 900,
+		VersionCheck: versionCheck,
 	}, nil
 
 }
@@ -144,16 +148,14 @@ func newConfig(buildConfig []byte, rewriteDockerRepositoryName bool) (*Config, e
 	for _, e := range customStrategy.Env {
 		env[e.Name] = e.Value
 	}
-
-	var applicationType ApplicationType = JavaLeveransepakke
-	if appType, err := findEnv(env, "APPLICATION_TYPE"); err == nil {
-		if strings.ToUpper(appType) == NodeJs {
-			applicationType = NodeJsLeveransepakke
-		} else if strings.ToUpper(appType) == Doozer {
-			applicationType = DoozerLeveranse
-		}
+	if err := rejectPlatformOwnedEnv(env); err != nil {
+		return nil, err
 	}
 
+	appType, _ := findEnv(env, "APPLICATION_TYPE")
+	applicationTypeSpec := readApplicationType(appType)
+	applicationType := applicationTypeSpec.Type
+
 	var buildStrategy = Docker
 	if value, err := findEnv(env, "BUILD_STRATEGY"); err == nil {
 		if strings.Contains(strings.ToLower(value), Buildah) {
@@ -195,6 +197,9 @@ func newConfig(buildConfig []byte, rewriteDockerRepositoryName bool) (*Config, e
 		nexusAccess.NexusUrl = data["nexusUrl"].(string)
 		nexusAccess.Username = data["username"].(string)
 		nexusAccess.Password = data["password"].(string)
+		if pypiUrl, ok := data["pypiUrl"].(string); ok {
+			nexusAccess.PypiUrl = pypiUrl
+		}
 	} else {
 		logrus.Warnf("Could not read nexus config at %s, error: %s", secretPath, err)
 	}
@@ -223,21 +228,20 @@ func newConfig(buildConfig []byte, rewriteDockerRepositoryName bool) (*Config, e
 	} else {
 		return nil, err
 	}
+	packagingValue, packagingErr := findEnv(env, "PACKAGING")
+	packaging, err := applicationTypeSpec.ReadPackaging(packagingValue, packagingErr == nil)
+	if err != nil {
+		return nil, err
+	}
 	if classifier, err := findEnv(env, "CLASSIFIER"); err == nil {
 		applicationSpec.MavenGav.Classifier = Classifier(classifier)
 	} else {
-		if applicationType == JavaLeveransepakke {
-			applicationSpec.MavenGav.Classifier = Leveransepakke
-		} else if applicationType == NodeJsLeveransepakke {
-			applicationSpec.MavenGav.Classifier = Webleveransepakke
-		} else {
-			applicationSpec.MavenGav.Classifier = Doozerleveransepakke
-		}
+		// A single file, like a Spring Boot jar or a wheel, has no classifier
+		applicationSpec.MavenGav.Classifier = applicationTypeSpec.DefaultClassifier(packaging)
 	}
-	if applicationType == JavaLeveransepakke || applicationType == DoozerLeveranse {
-		applicationSpec.MavenGav.Type = ZipPackaging
-	} else {
-		applicationSpec.MavenGav.Type = TgzPackaging
+	applicationSpec.MavenGav.Type = packaging
+	if metadata, err := findEnv(env, "DELIVERABLE_METADATA"); err == nil {
+		applicationSpec.DeliverableMetadata = metadata
 	}
 
 	if baseSpec, err := findBaseImage(env); err == nil {
@@ -321,6 +325,22 @@ func newConfig(buildConfig []byte, rewriteDockerRepositoryName bool) (*Config, e
 		}
 	}
 
+	if caCertificates, err := findEnv(env, "CA_CERTIFICATES"); err == nil {
+		dockerSpec.CaCertificates, err = parseCaCertificates(caCertificates, env)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+
+	versionCheck := VersionCheckWarn
+	if value, err := findEnv(env, "VERSION_CHECK"); err == nil {
+		versionCheck, err = parseVersionCheck(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	builderSpec := BuilderSpec{}
 
 	if builderVersion, present := os.LookupEnv("APP_VERSION"); present {
@@ -331,6 +351,13 @@ func newConfig(buildConfig []byte, rewriteDockerRepositoryName bool) (*Config, e
 		builderSpec.Version = "local"
 	}
 
+	platformConfig, err := LoadPlatformConfig(PlatformConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	dockerSpec.TemplateDir = platformConfig.TemplateDir
+	dockerSpec.AllowRootUser = platformConfig.AllowRootUser
+
 	outputKind := build.Spec.Output.To.Kind
 	logrus.Debugf("Output Kind is: %s ", outputKind)
 	if outputKind == "DockerImage" {
@@ -394,6 +421,8 @@ func newConfig(buildConfig []byte, rewriteDockerRepositoryName bool) (*Config, e
 		BuildStrategy:   buildStrategy,
 		TlsVerify:       tlsVerify,
 		BuildTimeout:    buildTimeout,
+		BaseImagePolicy: platformConfig.BaseImagePolicy,
+		VersionCheck:    versionCheck,
 	}
 	return c, nil
 }
@@ -443,6 +472,41 @@ func resolveIpIfInternalRegistry(registryWithPort string, rewrite bool) (string,
 	return registryWithPort, nil
 }
 
+// The application type defaults to java. Unknown types are built as java too, with a warning
+func readApplicationType(name string) ApplicationTypeSpec {
+	if name != "" {
+		spec, err := FindApplicationType(name)
+		if err == nil {
+			return spec
+		}
+		logrus.Warnf("%s. Building as java", err)
+	}
+	spec, err := ApplicationTypeSpecFor(JavaLeveransepakke)
+	if err != nil {
+		// The java package is not linked in, eg. in a test
+		spec.Type = JavaLeveransepakke
+	}
+	return spec
+}
+
+// CA_CERTIFICATES is embedded, mounted or both. The mounted PEM files are read from CA_CERTIFICATES_DIR
+func parseCaCertificates(value string, env map[string]string) (CaCertificatesSpec, error) {
+	spec := CaCertificatesSpec{}
+	value = strings.ToLower(value)
+	if value != "embedded" && value != "mounted" && value != "both" {
+		return spec, errors.Errorf("Illegal value %s of CA_CERTIFICATES. Use embedded, mounted or both", value)
+	}
+	spec.Embedded = value != "mounted"
+	if value != "embedded" {
+		directory, err := findEnv(env, "CA_CERTIFICATES_DIR")
+		if err != nil {
+			directory = DefaultCaCertificatesDir
+		}
+		spec.Directory = directory
+	}
+	return spec, nil
+}
+
 func findBaseImage(env map[string]string) (DockerBaseImageSpec, error) {
 	baseSpec := DockerBaseImageSpec{}
 	if baseImage, err := findEnv(env, "DOCKER_BASE_IMAGE"); err == nil {
@@ -32,6 +32,29 @@ func TestNodeJSLeveransePakkeConfig(t *testing.T) {
 	assert.Equal(t, "testgroup", c.ApplicationSpec.MavenGav.GroupId)
 }
 
+func TestReadDoozerSingleFileConfig(t *testing.T) {
+	r := config.NewFileConfigReader("../../testdata/doozerbuild.json")
+	c, err := r.ReadConfig()
+
+	assert.NoError(t, err)
+	assert.Equal(t, config.DoozerLeveranse, c.ApplicationType)
+	assert.Equal(t, "doozer-test-app", c.ApplicationSpec.MavenGav.ArtifactId)
+	assert.Equal(t, config.PackageType("bin"), c.ApplicationSpec.MavenGav.Type)
+	assert.Equal(t, config.Classifier(""), c.ApplicationSpec.MavenGav.Classifier)
+	assert.Contains(t, c.ApplicationSpec.DeliverableMetadata, "doozer-test-app.bin")
+}
+
+func TestReadPythonWheelConfig(t *testing.T) {
+	r := config.NewFileConfigReader("../../testdata/pythonbuild.json")
+	c, err := r.ReadConfig()
+
+	assert.NoError(t, err)
+	assert.Equal(t, config.PythonLeveranse, c.ApplicationType)
+	assert.Equal(t, "python-test-app", c.ApplicationSpec.MavenGav.ArtifactId)
+	assert.Equal(t, config.WhlPackaging, c.ApplicationSpec.MavenGav.Type)
+	assert.Equal(t, config.Classifier(""), c.ApplicationSpec.MavenGav.Classifier)
+}
+
 func TestTagWithConfig(t *testing.T) {
 	r := config.NewFileConfigReader("../../testdata/build.json")
 	c, err := r.ReadConfig()
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+)
+
+/*
+Where the platform mounts the configuration of Architect, like the Nexus secret in /u01/nexus/nexus.json.
+
+The env of a custom build is the env of the BuildConfig, so the settings that restrict the application teams can not be
+read from it. They are read from this file instead:
+
+	{"baseImagePolicy": {"baseImages": [{"name": "aurora/wingnut11", "versions": ">= 1, < 3"}]},
+	 "templateDir": "/u01/architect/templates", "allowRootUser": false}
+*/
+const PlatformConfigFile = "/u01/architect/platform.json"
+
+// The names of the settings that used to be read from the env. A BuildConfig setting one of them is rejected
+var platformOwnedEnv = []string{"BASE_IMAGE_POLICY", "BASE_IMAGE_POLICY_FILE", "TEMPLATE_DIR", "ALLOW_ROOT_USER"}
+
+// PlatformConfig is owned by the platform, not by the application teams
+type PlatformConfig struct {
+	BaseImagePolicy *BaseImagePolicy `json:"baseImagePolicy"`
+	// Folder with templates overriding the ones embedded in Architect
+	TemplateDir string `json:"templateDir"`
+	// Whether a deliverable may make the image run as root
+	AllowRootUser bool `json:"allowRootUser"`
+}
+
+// LoadPlatformConfig returns an empty configuration if the file does not exist
+func LoadPlatformConfig(path string) (*PlatformConfig, error) {
+	platformConfig := &PlatformConfig{}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		logrus.Debugf("No platform configuration in %s", path)
+		return platformConfig, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "Could not read platform configuration %s", path)
+	}
+	if err := json.Unmarshal(content, platformConfig); err != nil {
+		return nil, errors.Wrapf(err, "Could not parse platform configuration %s", path)
+	}
+	if platformConfig.BaseImagePolicy != nil {
+		if err := platformConfig.BaseImagePolicy.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return platformConfig, nil
+}
+
+// rejectPlatformOwnedEnv fails the build if the BuildConfig tries to set what the platform owns
+func rejectPlatformOwnedEnv(env map[string]string) error {
+	for _, name := range platformOwnedEnv {
+		if _, present := env[name]; present {
+			return errors.Errorf("%s can not be set in the BuildConfig. It is configured by the platform in %s",
+				name, PlatformConfigFile)
+		}
+	}
+	return nil
+}
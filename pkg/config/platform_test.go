@@ -0,0 +1,37 @@
+package config_test
+
+import (
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPlatformConfig(t *testing.T) {
+	folder, err := ioutil.TempDir("", "platform-config-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+
+	platformConfig, err := config.LoadPlatformConfig(filepath.Join(folder, "missing.json"))
+	assert.NoError(t, err)
+	assert.Nil(t, platformConfig.BaseImagePolicy)
+
+	path := filepath.Join(folder, "platform.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"baseImagePolicy": `+policyJson+`, "templateDir": "/u01/templates", "allowRootUser": true}`), 0644))
+	platformConfig, err = config.LoadPlatformConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "/u01/templates", platformConfig.TemplateDir)
+	assert.True(t, platformConfig.AllowRootUser)
+	assert.NoError(t, platformConfig.BaseImagePolicy.Allows(config.DockerBaseImageSpec{BaseImage: "aurora/wingnut17", BaseVersion: "1"}))
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"baseImagePolicy": {"baseImages": [{"versions": "1"}]}}`), 0644))
+	_, err = config.LoadPlatformConfig(path)
+	assert.EqualError(t, err, "Base image policy contains a base image without name")
+}
+
+func TestThatTheBuildConfigCanNotSetThePlatformConfig(t *testing.T) {
+	_, err := config.NewFileConfigReader("../../testdata/build-policy-in-env.json").ReadConfig()
+	assert.EqualError(t, err, "BASE_IMAGE_POLICY can not be set in the BuildConfig. It is configured by the platform in /u01/architect/platform.json")
+}
@@ -3,6 +3,7 @@ package runtime
 import (
 	"fmt"
 	"github.com/skatteetaten/architect/pkg/util"
+	"sort"
 	"strings"
 )
 
@@ -12,9 +13,67 @@ type ImageInfo struct {
 	Enviroment               map[string]string
 }
 
+// The label on the base image telling which family of base images it belongs to, e.g. java or nodejs
+const ImageArchitectureLabel = "www.skatteetaten.no-imageArchitecture"
+
 type BaseImage struct {
 	DockerImage
 	ImageInfo *ImageInfo
+	// Where the base image was chosen, the BuildConfig or the deliverable
+	Source string
+}
+
+// GetImageArchitecture returns the value of the image architecture label, or an empty string if the label is not set
+func (m *BaseImage) GetImageArchitecture() string {
+	if m.ImageInfo == nil {
+		return ""
+	}
+	return m.ImageInfo.Labels[ImageArchitectureLabel]
+}
+
+// The label on the base image telling which version of the Dockerfile templates it supports
+const TemplateVersionLabel = "www.skatteetaten.no-templateVersion"
+
+// GetTemplateVersion returns the value of the template version label, or an empty string if the label is not set
+func (m *BaseImage) GetTemplateVersion() string {
+	if m.ImageInfo == nil {
+		return ""
+	}
+	return m.ImageInfo.Labels[TemplateVersionLabel]
+}
+
+// The label on the base image telling which command a HEALTHCHECK can poll the readiness url with, wget or curl
+const HealthCheckToolLabel = "www.skatteetaten.no-healthcheckTool"
+
+// GetHealthCheckTool returns the value of the healthcheck tool label, or an empty string if the label is not set
+func (m *BaseImage) GetHealthCheckTool() string {
+	if m.ImageInfo == nil {
+		return ""
+	}
+	return m.ImageInfo.Labels[HealthCheckToolLabel]
+}
+
+// The label on the base image telling that its nginx has the brotli module, with the value true
+const NginxBrotliLabel = "www.skatteetaten.no-nginxBrotli"
+
+// HasNginxBrotli tells if the nginx brotli label is true
+func (m *BaseImage) HasNginxBrotli() bool {
+	if m.ImageInfo == nil {
+		return false
+	}
+	return m.ImageInfo.Labels[NginxBrotliLabel] == "true"
+}
+
+// UnsupportedImageArchitectureError lists the supported architectures, so the user can pick a base image that works
+func UnsupportedImageArchitectureError(architecture string, supported []string) error {
+	sorted := append([]string(nil), supported...)
+	sort.Strings(sorted)
+	if architecture == "" {
+		return fmt.Errorf("The base image provided has no %s label. Supported values are [%s]. Make sure you use the latest version",
+			ImageArchitectureLabel, strings.Join(sorted, ", "))
+	}
+	return fmt.Errorf("The base image provided has an unsupported %s label %q. Supported values are [%s]",
+		ImageArchitectureLabel, architecture, strings.Join(sorted, ", "))
 }
 
 // The Docker naming scheme sucks..
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"github.com/skatteetaten/architect/pkg/templates"
 	"strings"
 	"time"
 )
@@ -11,8 +12,10 @@ const (
 	JavaLeveransepakke   ApplicationType = "JavaLeveransepakke"
 	NodeJsLeveransepakke ApplicationType = "NodeJsLeveranse"
 	DoozerLeveranse      ApplicationType = "DoozerLeveranse"
+	PythonLeveranse      ApplicationType = "PythonLeveranse"
 	NodeJs                               = "NODEJS"
 	Doozer                               = "DOOZER"
+	Python                               = "PYTHON"
 )
 
 type PackageType string
@@ -20,6 +23,9 @@ type PackageType string
 const (
 	ZipPackaging PackageType = "zip"
 	TgzPackaging PackageType = "tgz"
+	JarPackaging PackageType = "jar"
+	WarPackaging PackageType = "war"
+	WhlPackaging PackageType = "whl"
 )
 
 type Classifier string
@@ -28,6 +34,7 @@ const (
 	Leveransepakke       Classifier = "Leveransepakke"
 	Webleveransepakke    Classifier = "Webleveransepakke"
 	Doozerleveransepakke Classifier = "Doozerleveransepakke"
+	Pythonleveransepakke Classifier = "Pythonleveransepakke"
 )
 
 const (
@@ -47,21 +54,29 @@ type Config struct {
 	TlsVerify       bool
 	BuildTimeout    time.Duration
 	NoPush          bool
+	// The base images the deliverable may choose. Nil if no policy is configured
+	BaseImagePolicy *BaseImagePolicy
+	// What to do when the version in the deliverable differs from the version it is built as
+	VersionCheck VersionCheck
 }
 
 type NexusAccess struct {
 	Username string
 	Password string
 	NexusUrl string
+	// Optional. The PyPI index in Nexus that python requirements are installed from
+	PypiUrl string
 }
 
 func (n NexusAccess) String() string {
-	return "{Username:" + n.Username + " Password:****** NexusUrl:" + n.NexusUrl + "}"
+	return "{Username:" + n.Username + " Password:****** NexusUrl:" + n.NexusUrl + " PypiUrl:" + n.PypiUrl + "}"
 }
 
 type ApplicationSpec struct {
 	MavenGav      MavenGav
 	BaseImageSpec DockerBaseImageSpec
+	// The content of openshift.json for deliverables without it, like a Spring Boot jar
+	DeliverableMetadata string
 }
 
 type MavenGav struct {
@@ -96,6 +111,26 @@ type DockerSpec struct {
 	TagWith      string
 	RetagWith    string
 	TagOverwrite bool
+	//CA certificates to add to the truststores in the image
+	CaCertificates CaCertificatesSpec
+	//Folder with templates overriding the ones embedded in Architect
+	TemplateDir string
+	//The templates the image is built with. Nil is the default template version
+	Templates *templates.TemplateSet
+	//Whether a deliverable may make the image run as root
+	AllowRootUser bool
+	//The command of the base image a HEALTHCHECK uses. No HEALTHCHECK when empty
+	HealthCheckTool string
+}
+
+// The CA certificates can come from the bundle embedded in Architect, from a directory of PEM files, or both
+type CaCertificatesSpec struct {
+	Embedded  bool
+	Directory string
+}
+
+func (m CaCertificatesSpec) Enabled() bool {
+	return m.Embedded || m.Directory != ""
 }
 
 type BuilderSpec struct {
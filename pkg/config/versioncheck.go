@@ -0,0 +1,49 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+/*
+VersionCheck tells what to do when the version embedded in the deliverable differs from the version it is built as.
+It is set with VERSION_CHECK in the BuildConfig, or --version-check for local builds.
+*/
+type VersionCheck string
+
+const (
+	VersionCheckOff  VersionCheck = "off"
+	VersionCheckWarn VersionCheck = "warn"
+	VersionCheckFail VersionCheck = "fail"
+)
+
+// The version Nexus gives a SNAPSHOT when it is deployed, like 1.2.3-20200131.120000-4
+var snapshotTimestamp = regexp.MustCompile(`-\d{8}\.\d{6}-\d+$`)
+
+func parseVersionCheck(value string) (VersionCheck, error) {
+	switch check := VersionCheck(strings.ToLower(strings.TrimSpace(value))); check {
+	case VersionCheckOff, VersionCheckWarn, VersionCheckFail:
+		return check, nil
+	}
+	return "", errors.Errorf("Illegal VERSION_CHECK %s. Use off, warn or fail", value)
+}
+
+/*
+MatchesVersion is true if the version embedded in the deliverable is the version the deliverable is built as. The
+SNAPSHOT in a version is rewritten to a timestamp when it is deployed to Nexus, so 1.2.3-SNAPSHOT matches
+1.2.3-20200131.120000-4 both ways.
+*/
+func (m MavenGav) MatchesVersion(deliverableVersion string) bool {
+	if deliverableVersion == m.Version {
+		return true
+	}
+	return snapshotBase(deliverableVersion) == snapshotBase(m.Version)
+}
+
+func snapshotBase(version string) string {
+	if snapshotTimestamp.MatchString(version) {
+		return snapshotTimestamp.ReplaceAllString(version, "") + "-SNAPSHOT"
+	}
+	return version
+}
@@ -0,0 +1,24 @@
+package config_test
+
+import (
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMatchesVersion(t *testing.T) {
+	release := config.MavenGav{Version: "1.4.0"}
+	assert.True(t, release.MatchesVersion("1.4.0"))
+	assert.False(t, release.MatchesVersion("1.5.0"))
+	assert.False(t, release.MatchesVersion("1.4.0-SNAPSHOT"))
+
+	snapshot := config.MavenGav{Version: "feature-abc-SNAPSHOT"}
+	assert.True(t, snapshot.MatchesVersion("feature-abc-SNAPSHOT"))
+	assert.True(t, snapshot.MatchesVersion("feature-abc-20200131.120000-4"))
+	assert.False(t, snapshot.MatchesVersion("feature-xyz-20200131.120000-4"))
+
+	timestamped := config.MavenGav{Version: "1.4.0-20200131.120000-4"}
+	assert.True(t, timestamped.MatchesVersion("1.4.0-SNAPSHOT"))
+	assert.True(t, timestamped.MatchesVersion("1.4.0-20200130.080000-3"))
+	assert.False(t, timestamped.MatchesVersion("1.4.0"))
+}
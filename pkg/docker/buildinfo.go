@@ -0,0 +1,76 @@
+package docker
+
+import (
+	"net/url"
+)
+
+const (
+	LABEL_OCI_CREATED  = "org.opencontainers.image.created"
+	LABEL_OCI_REVISION = "org.opencontainers.image.revision"
+	LABEL_OCI_SOURCE   = "org.opencontainers.image.source"
+	LABEL_OCI_VERSION  = "org.opencontainers.image.version"
+	LABEL_OCI_VENDOR   = "org.opencontainers.image.vendor"
+	// There are no OCI labels for the branch and the time the deliverable was built
+	LABEL_GIT_BRANCH     = "www.skatteetaten.no-gitBranch"
+	LABEL_GIT_BUILD_TIME = "www.skatteetaten.no-gitBuildTime"
+
+	Vendor = "Skatteetaten"
+)
+
+// BuildInfo is the git metadata found in the deliverable. Any of the fields may be empty
+type BuildInfo struct {
+	Revision  string
+	Branch    string
+	BuildTime string
+	Source    string
+}
+
+// NewBuildInfoFromGitProperties reads the git.properties created by git-commit-id-plugin
+func NewBuildInfoFromGitProperties(properties map[string]string) *BuildInfo {
+	revision := properties["git.commit.id.full"]
+	if revision == "" {
+		revision = properties["git.commit.id"]
+	}
+	return &BuildInfo{
+		Revision:  revision,
+		Branch:    properties["git.branch"],
+		BuildTime: properties["git.build.time"],
+		Source:    properties["git.remote.origin.url"],
+	}
+}
+
+/*
+OciLabels creates the standard labels of the image. version is the version of the application, and created is
+the time the image was built. The build info is optional.
+*/
+func OciLabels(buildInfo *BuildInfo, version string, created string) map[string]string {
+	labels := map[string]string{
+		LABEL_OCI_CREATED: created,
+		LABEL_OCI_VERSION: version,
+		LABEL_OCI_VENDOR:  Vendor,
+	}
+	if buildInfo == nil {
+		return labels
+	}
+	for label, value := range map[string]string{
+		LABEL_OCI_REVISION:   buildInfo.Revision,
+		LABEL_OCI_SOURCE:     withoutCredentials(buildInfo.Source),
+		LABEL_GIT_BRANCH:     buildInfo.Branch,
+		LABEL_GIT_BUILD_TIME: buildInfo.BuildTime,
+	} {
+		if value != "" {
+			labels[label] = value
+		}
+	}
+	return labels
+}
+
+// The remote url of a CI checkout may contain a token
+func withoutCredentials(source string) string {
+	sourceUrl, err := url.Parse(source)
+	if err != nil || sourceUrl.User == nil {
+		return source
+	}
+	sourceUrl.User = nil
+	return sourceUrl.String()
+}
@@ -0,0 +1,93 @@
+package docker
+
+import (
+	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"regexp"
+	"strings"
+)
+
+const (
+	ENV_LANG = "LANG"
+	ENV_HOME = "HOME"
+
+	// Used when neither the deliverable nor the base image has a timezone
+	DefaultTimezone = "Europe/Oslo"
+)
+
+// The variables set by Architect. The deliverable is not allowed to override them
+var architectEnv = map[string]bool{
+	ENV_APP_VERSION:                  true,
+	ENV_AURORA_VERSION:               true,
+	ENV_SNAPSHOT_TAG:                 true,
+	ENV_PUSH_EXTRA_TAGS:              true,
+	ENV_READINESS_CHECK_URL:          true,
+	ENV_READINESS_ON_MANAGEMENT_PORT: true,
+	IMAGE_BUILD_TIME:                 true,
+	ENV_SSL_CERT_FILE:                true,
+	ENV_HOME:                         true,
+}
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+var timezoneName = regexp.MustCompile(`^[A-Za-z0-9_+\-]+(/[A-Za-z0-9_+\-]+)*$`)
+var localeName = regexp.MustCompile(`^[A-Za-z0-9_.@\-]+$`)
+
+// DeliverableEnv is the timezone, locale and extra env the deliverable asks for in docker.timezone, docker.locale
+// and docker.env in openshift.json. It is embedded in the docker metadata of every application type
+type DeliverableEnv struct {
+	Timezone string            `json:"timezone"` // Optional. Defaults to TZ in the base image
+	Locale   string            `json:"locale"`   // Optional. Defaults to LANG in the base image
+	Env      map[string]string `json:"env"`      // Optional. Cannot override the env set by Architect
+}
+
+// AddBaseImageDefaults uses the timezone and locale of the base image when the deliverable has none
+func (m *DeliverableEnv) AddBaseImageDefaults(baseImage runtime.BaseImage) {
+	if baseImage.ImageInfo == nil {
+		return
+	}
+	if m.Timezone == "" {
+		m.Timezone = baseImage.ImageInfo.Enviroment[TZ]
+	}
+	if m.Locale == "" {
+		m.Locale = baseImage.ImageInfo.Enviroment[ENV_LANG]
+	}
+}
+
+/*
+AddTo adds the env of the deliverable to the env created by Architect. The timezone and locale are set as TZ and
+LANG if present. It fails if the deliverable tries to override a variable owned by Architect, or if a value cannot
+be written to the Dockerfile.
+*/
+func (m DeliverableEnv) AddTo(env map[string]string) error {
+	for key, value := range m.Env {
+		if !envName.MatchString(key) {
+			return errors.Errorf("Illegal name %s in docker.env", key)
+		}
+		if key == TZ || key == ENV_LANG {
+			return errors.Errorf("%s cannot be set in docker.env. Use docker.timezone or docker.locale", key)
+		}
+		if _, exists := env[key]; exists || architectEnv[key] {
+			return errors.Errorf("%s is set by Architect and cannot be overridden in docker.env", key)
+		}
+		if strings.ContainsAny(value, "\"\\\n\r") {
+			return errors.Errorf("Illegal value for %s in docker.env. Quotes, backslashes and newlines are not allowed", key)
+		}
+	}
+	if m.Timezone != "" && !timezoneName.MatchString(m.Timezone) {
+		return errors.Errorf("Illegal timezone %s. Use a name like Europe/Oslo", m.Timezone)
+	}
+	if m.Locale != "" && !localeName.MatchString(m.Locale) {
+		return errors.Errorf("Illegal locale %s. Use a name like en_US.UTF-8", m.Locale)
+	}
+
+	for key, value := range m.Env {
+		env[key] = value
+	}
+	if m.Timezone != "" {
+		env[TZ] = m.Timezone
+	}
+	if m.Locale != "" {
+		env[ENV_LANG] = m.Locale
+	}
+	return nil
+}
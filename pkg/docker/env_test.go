@@ -0,0 +1,41 @@
+package docker_test
+
+import (
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/skatteetaten/architect/pkg/docker"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeliverableEnv(t *testing.T) {
+	env := map[string]string{docker.ENV_APP_VERSION: "1.2.3"}
+	deliverableEnv := docker.DeliverableEnv{
+		Locale: "nb_NO.UTF-8",
+		Env:    map[string]string{"JAVA_TOOL_OPTIONS": "-Dfile.encoding=UTF-8"},
+	}
+	deliverableEnv.AddBaseImageDefaults(runtime.BaseImage{ImageInfo: &runtime.ImageInfo{
+		Enviroment: map[string]string{docker.TZ: "UTC", docker.ENV_LANG: "en_US.UTF-8"},
+	}})
+
+	assert.NoError(t, deliverableEnv.AddTo(env))
+	assert.Equal(t, map[string]string{
+		docker.ENV_APP_VERSION: "1.2.3",
+		"JAVA_TOOL_OPTIONS":    "-Dfile.encoding=UTF-8",
+		docker.TZ:              "UTC",
+		docker.ENV_LANG:        "nb_NO.UTF-8",
+	}, env)
+}
+
+func TestDeliverableEnvCannotOverrideArchitectEnv(t *testing.T) {
+	for name, deliverableEnv := range map[string]docker.DeliverableEnv{
+		"AURORA_VERSION is set by Architect and cannot be overridden in docker.env":             {Env: map[string]string{docker.ENV_AURORA_VERSION: "1"}},
+		"APP_VERSION is set by Architect and cannot be overridden in docker.env":                {Env: map[string]string{docker.ENV_APP_VERSION: "1"}},
+		"HOME is set by Architect and cannot be overridden in docker.env":                       {Env: map[string]string{docker.ENV_HOME: "/tmp"}},
+		"TZ cannot be set in docker.env. Use docker.timezone or docker.locale":                  {Env: map[string]string{docker.TZ: "UTC"}},
+		"Illegal name 1FOO in docker.env":                                                       {Env: map[string]string{"1FOO": "bar"}},
+		"Illegal value for FOO in docker.env. Quotes, backslashes and newlines are not allowed": {Env: map[string]string{"FOO": "\"bar"}},
+		"Illegal timezone Europe/Oslo\" TZ=x. Use a name like Europe/Oslo":                      {Timezone: "Europe/Oslo\" TZ=x"},
+	} {
+		assert.EqualError(t, deliverableEnv.AddTo(map[string]string{docker.ENV_APP_VERSION: "1.2.3"}), name)
+	}
+}
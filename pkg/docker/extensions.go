@@ -0,0 +1,199 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/util"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Where in the build folder the files copied by docker.extensions are put
+const ExtensionsFolder = "extensions"
+
+/*
+DockerExtensions are the small additions to the generated Dockerfile a deliverable may ask for in docker.extensions
+in openshift.json:
+
+	"extensions": {
+		"ports": [8443],
+		"volumes": ["/u01/data"],
+		"user": "1001",
+		"copies": [{"source": "config/app.conf", "destination": "/etc/app/app.conf"}],
+		"labels": {"no.skatteetaten.team": "aurora"}
+	}
+
+Only the extensions in allowedDockerExtensions are accepted, and every value is validated, so a deliverable
+cannot add arbitrary instructions like RUN.
+*/
+type DockerExtensions struct {
+	Ports   []int
+	Volumes []string
+	User    string
+	Copies  []ExtensionCopy
+	Labels  map[string]string
+}
+
+// ExtensionCopy copies a file in the application to a fixed path in the image
+type ExtensionCopy struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+var userName = regexp.MustCompile(`^([a-z_][a-z0-9_-]{0,31}|[0-9]+)(:([a-z_][a-z0-9_-]{0,31}|[0-9]+))?$`)
+var absolutePath = regexp.MustCompile(`^/[A-Za-z0-9._/-]*$`)
+var relativePath = regexp.MustCompile(`^[A-Za-z0-9._-][A-Za-z0-9._/-]*$`)
+var labelName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+var allowedDockerExtensions = map[string]func(value json.RawMessage, extensions *DockerExtensions) error{
+	"ports": func(value json.RawMessage, extensions *DockerExtensions) error {
+		if err := json.Unmarshal(value, &extensions.Ports); err != nil {
+			return errors.New("Value on ports should be a list of port numbers")
+		}
+		for _, port := range extensions.Ports {
+			if port < 1 || port > 65535 {
+				return errors.Errorf("Illegal port %d. Use a port between 1 and 65535", port)
+			}
+		}
+		return nil
+	},
+	"volumes": func(value json.RawMessage, extensions *DockerExtensions) error {
+		if err := json.Unmarshal(value, &extensions.Volumes); err != nil {
+			return errors.New("Value on volumes should be a list of paths")
+		}
+		for _, volume := range extensions.Volumes {
+			if err := ValidateAbsolutePath(volume); err != nil {
+				return errors.Wrap(err, "Illegal volume")
+			}
+		}
+		return nil
+	},
+	"user": func(value json.RawMessage, extensions *DockerExtensions) error {
+		if err := json.Unmarshal(value, &extensions.User); err != nil {
+			return errors.New("Value on user should be a user name or id")
+		}
+		return ValidateUser(extensions.User, false)
+	},
+	"copies": func(value json.RawMessage, extensions *DockerExtensions) error {
+		if err := json.Unmarshal(value, &extensions.Copies); err != nil {
+			return errors.New("Value on copies should be a list of source and destination")
+		}
+		for _, extensionCopy := range extensions.Copies {
+			if !relativePath.MatchString(extensionCopy.Source) || path.Clean(extensionCopy.Source) != extensionCopy.Source || strings.HasPrefix(extensionCopy.Source, "..") {
+				return errors.Errorf("Illegal source %s. Use a path relative to the application", extensionCopy.Source)
+			}
+			if err := ValidateAbsolutePath(extensionCopy.Destination); err != nil {
+				return errors.Wrap(err, "Illegal destination")
+			}
+		}
+		return nil
+	},
+	"labels": func(value json.RawMessage, extensions *DockerExtensions) error {
+		if err := json.Unmarshal(value, &extensions.Labels); err != nil {
+			return errors.New("Value on labels should be a map of label names and values")
+		}
+		for key, value := range extensions.Labels {
+			if !labelName.MatchString(key) {
+				return errors.Errorf("Illegal label %s", key)
+			}
+			if strings.ContainsAny(value, "\"\\\n\r") {
+				return errors.Errorf("Illegal value for label %s. Quotes, backslashes and newlines are not allowed", key)
+			}
+		}
+		return nil
+	},
+}
+
+// ValidateAbsolutePath validates a path in the image, like a volume or the destination of a copy
+func ValidateAbsolutePath(value string) error {
+	if !absolutePath.MatchString(value) || path.Clean(value) != value {
+		return errors.Errorf("%s is not an absolute path", value)
+	}
+	return nil
+}
+
+// ValidateUser validates the user an image runs as, or the owner of a file. Root is only accepted with allowRoot
+func ValidateUser(user string, allowRoot bool) error {
+	if !userName.MatchString(user) {
+		return errors.Errorf("Illegal user %s. Use a user name or id, optionally with a group", user)
+	}
+	if name := strings.Split(user, ":")[0]; !allowRoot && (name == "root" || name == "0") {
+		return errors.New("The image cannot run as root")
+	}
+	return nil
+}
+
+// NewDockerExtensions validates docker.extensions in openshift.json. It returns nil when there are no extensions
+func NewDockerExtensions(extensions map[string]json.RawMessage) (*DockerExtensions, error) {
+	if len(extensions) == 0 {
+		return nil, nil
+	}
+	dockerExtensions := &DockerExtensions{}
+	for name, value := range extensions {
+		validator, allowed := allowedDockerExtensions[name]
+		if !allowed {
+			return nil, errors.Errorf("Extension %s is not allowed in docker.extensions", name)
+		}
+		if err := validator(value, dockerExtensions); err != nil {
+			return nil, errors.Wrap(err, "Illegal docker.extensions")
+		}
+	}
+	return dockerExtensions, nil
+}
+
+// CopyFiles copies the sources of the copies in the application folder to the extensions folder of the build folder
+func (m *DockerExtensions) CopyFiles(applicationFolder string, buildFolder string) error {
+	if m == nil {
+		return nil
+	}
+	for i, extensionCopy := range m.Copies {
+		if err := util.CopyFile(filepath.Join(applicationFolder, filepath.FromSlash(extensionCopy.Source)),
+			filepath.Join(buildFolder, m.contextPath(i))); err != nil {
+			return errors.Wrapf(err, "Failed to copy %s in docker.extensions", extensionCopy.Source)
+		}
+	}
+	return nil
+}
+
+// Each copy gets its own folder, so sources with the same name do not collide
+func (m *DockerExtensions) contextPath(i int) string {
+	return path.Join(ExtensionsFolder, strconv.Itoa(i), path.Base(m.Copies[i].Source))
+}
+
+// AddLabels adds the labels in the extensions. Existing labels have precedence
+func (m *DockerExtensions) AddLabels(labels map[string]string) {
+	if m == nil {
+		return
+	}
+	for key, value := range m.Labels {
+		if _, exists := labels[key]; !exists {
+			labels[key] = value
+		}
+	}
+}
+
+// Instructions renders the extensions as Dockerfile instructions. USER goes last, so the copies are done by the
+// user of the base image
+func (m *DockerExtensions) Instructions() string {
+	if m == nil {
+		return ""
+	}
+	instructions := make([]string, 0, len(m.Copies)+len(m.Ports)+2)
+	for i, extensionCopy := range m.Copies {
+		instructions = append(instructions, fmt.Sprintf("COPY ./%s %s", m.contextPath(i), extensionCopy.Destination))
+	}
+	for _, port := range m.Ports {
+		instructions = append(instructions, fmt.Sprintf("EXPOSE %d", port))
+	}
+	if len(m.Volumes) > 0 {
+		instructions = append(instructions, fmt.Sprintf("VOLUME [\"%s\"]", strings.Join(m.Volumes, "\", \"")))
+	}
+	if m.User != "" {
+		instructions = append(instructions, "USER "+m.User)
+	}
+	return strings.Join(instructions, "\n")
+}
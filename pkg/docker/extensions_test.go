@@ -0,0 +1,78 @@
+package docker_test
+
+import (
+	"encoding/json"
+	"github.com/skatteetaten/architect/pkg/docker"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDockerExtensions(t *testing.T) {
+	extensions, err := docker.NewDockerExtensions(map[string]json.RawMessage{
+		"ports":   json.RawMessage(`[8443, 9090]`),
+		"volumes": json.RawMessage(`["/u01/data", "/u01/cache"]`),
+		"user":    json.RawMessage(`"1001:0"`),
+		"copies":  json.RawMessage(`[{"source": "config/app.conf", "destination": "/etc/app/app.conf"}]`),
+		"labels":  json.RawMessage(`{"no.skatteetaten.team": "aurora", "maintainer": "ignored"}`),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "COPY ./extensions/0/app.conf /etc/app/app.conf\n"+
+		"EXPOSE 8443\n"+
+		"EXPOSE 9090\n"+
+		"VOLUME [\"/u01/data\", \"/u01/cache\"]\n"+
+		"USER 1001:0", extensions.Instructions())
+
+	labels := map[string]string{"maintainer": "wrench@sits.no"}
+	extensions.AddLabels(labels)
+	assert.Equal(t, map[string]string{"maintainer": "wrench@sits.no", "no.skatteetaten.team": "aurora"}, labels)
+}
+
+func TestDockerExtensionsCopyFiles(t *testing.T) {
+	applicationFolder, err := ioutil.TempDir("", "application")
+	assert.NoError(t, err)
+	defer os.RemoveAll(applicationFolder)
+	buildFolder, err := ioutil.TempDir("", "build")
+	assert.NoError(t, err)
+	defer os.RemoveAll(buildFolder)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(applicationFolder, "config"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(applicationFolder, "config", "app.conf"), []byte("a=b"), 0644))
+
+	extensions, err := docker.NewDockerExtensions(map[string]json.RawMessage{
+		"copies": json.RawMessage(`[{"source": "config/app.conf", "destination": "/etc/app/app.conf"},
+			{"source": "missing.conf", "destination": "/etc/app/missing.conf"}]`),
+	})
+	assert.NoError(t, err)
+	assert.Error(t, extensions.CopyFiles(applicationFolder, buildFolder))
+
+	content, err := ioutil.ReadFile(filepath.Join(buildFolder, "extensions", "0", "app.conf"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a=b", string(content))
+}
+
+func TestNoDockerExtensions(t *testing.T) {
+	extensions, err := docker.NewDockerExtensions(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, extensions)
+	assert.Equal(t, "", extensions.Instructions())
+	assert.NoError(t, extensions.CopyFiles("/does/not/exist", "/does/not/exist"))
+}
+
+func TestIllegalDockerExtensions(t *testing.T) {
+	for expected, extension := range map[string]map[string]json.RawMessage{
+		"Extension run is not allowed in docker.extensions":                                                         {"run": json.RawMessage(`"rm -rf /"`)},
+		"Illegal docker.extensions: Illegal port 70000. Use a port between 1 and 65535":                             {"ports": json.RawMessage(`[70000]`)},
+		"Illegal docker.extensions: Illegal volume: u01/data is not an absolute path":                               {"volumes": json.RawMessage(`["u01/data"]`)},
+		"Illegal docker.extensions: The image cannot run as root":                                                   {"user": json.RawMessage(`"0:0"`)},
+		"Illegal docker.extensions: Illegal user 1001\nRUN id. Use a user name or id, optionally with a group":      {"user": json.RawMessage(`"1001\nRUN id"`)},
+		"Illegal docker.extensions: Illegal source ../secret. Use a path relative to the application":               {"copies": json.RawMessage(`[{"source": "../secret", "destination": "/etc/secret"}]`)},
+		"Illegal docker.extensions: Illegal value for label team. Quotes, backslashes and newlines are not allowed": {"labels": json.RawMessage(`{"team": "a\" b=\"c"}`)},
+	} {
+		_, err := docker.NewDockerExtensions(extension)
+		assert.EqualError(t, err, expected)
+	}
+}
@@ -0,0 +1,156 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	LABEL_READINESS_CHECK_URL          = "www.skatteetaten.no-readinessUrl"
+	LABEL_READINESS_ON_MANAGEMENT_PORT = "www.skatteetaten.no-readinessOnManagementPort"
+)
+
+// The commands a base image can poll the readiness url with, and their arguments before the url
+var healthCheckTools = map[string][]string{
+	"wget": {"wget", "--spider", "-q"},
+	"curl": {"curl", "-fsS", "-o", "/dev/null"},
+}
+
+// The characters of a url in RFC 3986. Quotes, whitespace and backslashes are not among them. Neither is $, since
+// Docker expands variables in the ENV and LABEL the url is written to
+var readinessURLCharacters = regexp.MustCompile(`^[A-Za-z0-9._~:/?#\[\]@!&'()*+,;=%-]+$`)
+
+// HealthCheck is rendered as a HEALTHCHECK instruction in the Dockerfile. It polls the readiness url of the application
+type HealthCheck struct {
+	ReadinessURL     string
+	OnManagementPort bool
+	Interval         string
+	Timeout          string
+	Retries          int
+	// wget or curl, whichever the base image has
+	Tool string
+}
+
+/*
+NewHealthCheck returns nil if there is no readiness url to check. The tool is the command the base image declares in
+its healthcheck tool label. Without it there is no HEALTHCHECK, since the image may have neither wget nor curl.
+*/
+func NewHealthCheck(readinessURL string, onManagementPort string, interval string, timeout string, retries int, tool string) (*HealthCheck, error) {
+	if readinessURL == "" {
+		return nil, nil
+	}
+	if err := validateReadinessURL(readinessURL); err != nil {
+		return nil, err
+	}
+	for _, duration := range []string{interval, timeout} {
+		if duration == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(duration); err != nil {
+			return nil, errors.Errorf("Illegal healthcheck duration %s. Use a duration like 30s or 1m30s", duration)
+		}
+	}
+	if retries < 0 {
+		return nil, errors.Errorf("Illegal healthcheck retries %d", retries)
+	}
+	if tool == "" {
+		logrus.Warn("The base image does not tell which command a healthcheck can use. The image gets no HEALTHCHECK")
+		return nil, nil
+	}
+	if _, ok := healthCheckTools[tool]; !ok {
+		return nil, errors.Errorf("Unsupported healthcheck tool %s in the base image. Use wget or curl", tool)
+	}
+	return &HealthCheck{
+		ReadinessURL:     readinessURL,
+		OnManagementPort: IsReadinessOnManagementPort(onManagementPort),
+		Interval:         interval,
+		Timeout:          timeout,
+		Retries:          retries,
+		Tool:             tool,
+	}, nil
+}
+
+// A readiness url is a path on the application, like /health, or an absolute http or https url
+func validateReadinessURL(readinessURL string) error {
+	parsed, err := url.Parse(readinessURL)
+	if err != nil || !readinessURLCharacters.MatchString(readinessURL) {
+		return errors.Errorf("Illegal readiness url %q", readinessURL)
+	}
+	if parsed.Scheme == "" && parsed.Host == "" && !strings.HasPrefix(readinessURL, "//") {
+		return nil
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.Errorf("Illegal readiness url %q. Use a path or an http or https url", readinessURL)
+	}
+	return nil
+}
+
+func IsReadinessOnManagementPort(value string) bool {
+	return strings.ToLower(strings.TrimSpace(value)) == "true"
+}
+
+// ReadinessEnv returns the env and labels describing the readiness check. Empty values are left out
+func ReadinessEnv(readinessURL string, onManagementPort string) (map[string]string, map[string]string, error) {
+	env := make(map[string]string)
+	labels := make(map[string]string)
+	if readinessURL != "" {
+		if err := validateReadinessURL(readinessURL); err != nil {
+			return nil, nil, err
+		}
+		env[ENV_READINESS_CHECK_URL] = readinessURL
+		labels[LABEL_READINESS_CHECK_URL] = readinessURL
+	}
+	if onManagementPort != "" {
+		env[ENV_READINESS_ON_MANAGEMENT_PORT] = onManagementPort
+		labels[LABEL_READINESS_ON_MANAGEMENT_PORT] = onManagementPort
+	}
+	return env, labels, nil
+}
+
+/*
+command is the exec form of the check. An absolute url is passed straight to the tool. A path needs the port the
+application listens on when the container runs, so the shell expands the port variable, and gets the path as an
+argument. The url is never part of the shell script.
+*/
+func (h HealthCheck) command() []string {
+	tool := healthCheckTools[h.Tool]
+	if strings.HasPrefix(h.ReadinessURL, "http://") || strings.HasPrefix(h.ReadinessURL, "https://") {
+		return append(append([]string{}, tool...), h.ReadinessURL)
+	}
+	port := "${HTTP_PORT:-8080}"
+	if h.OnManagementPort {
+		port = "${MANAGEMENT_HTTP_PORT:-8081}"
+	}
+	script := strings.Join(tool, " ") + ` "http://localhost:` + port + `$1" || exit 1`
+	return []string{"/bin/sh", "-c", script, "healthcheck", "/" + strings.TrimPrefix(h.ReadinessURL, "/")}
+}
+
+func (h HealthCheck) String() string {
+	options := make([]string, 0, 3)
+	if h.Interval != "" {
+		options = append(options, "--interval="+h.Interval)
+	}
+	if h.Timeout != "" {
+		options = append(options, "--timeout="+h.Timeout)
+	}
+	if h.Retries > 0 {
+		options = append(options, fmt.Sprintf("--retries=%d", h.Retries))
+	}
+	instruction := "HEALTHCHECK"
+	if len(options) > 0 {
+		instruction += " " + strings.Join(options, " ")
+	}
+	// The json array is the exec form. Urls keep their & instead of \u0026
+	command := new(bytes.Buffer)
+	encoder := json.NewEncoder(command)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(h.command())
+	return instruction + " CMD " + strings.TrimSpace(command.String())
+}
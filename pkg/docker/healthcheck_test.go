@@ -0,0 +1,46 @@
+package docker_test
+
+import (
+	"github.com/skatteetaten/architect/pkg/docker"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHealthCheckOnApplicationPort(t *testing.T) {
+	healthCheck, err := docker.NewHealthCheck("health", "", "", "5s", 0, "curl")
+	assert.NoError(t, err)
+	assert.Equal(t, `HEALTHCHECK --timeout=5s CMD ["/bin/sh","-c","curl -fsS -o /dev/null \"http://localhost:${HTTP_PORT:-8080}$1\" || exit 1","healthcheck","/health"]`,
+		healthCheck.String())
+}
+
+func TestHealthCheckWithAbsoluteURL(t *testing.T) {
+	healthCheck, err := docker.NewHealthCheck("http://localhost:8080/health?a=1&b=2", "true", "", "", 0, "wget")
+	assert.NoError(t, err)
+	assert.Equal(t, `HEALTHCHECK CMD ["wget","--spider","-q","http://localhost:8080/health?a=1&b=2"]`, healthCheck.String())
+}
+
+func TestHealthCheckNeedsAToolInTheBaseImage(t *testing.T) {
+	healthCheck, err := docker.NewHealthCheck("/health", "", "", "", 0, "")
+	assert.NoError(t, err)
+	assert.Nil(t, healthCheck)
+
+	_, err = docker.NewHealthCheck("/health", "", "", "", 0, "nc")
+	assert.EqualError(t, err, "Unsupported healthcheck tool nc in the base image. Use wget or curl")
+}
+
+func TestThatIllegalReadinessURLsAreRejected(t *testing.T) {
+	for _, readinessURL := range []string{
+		"/health\" || true\nUSER root",
+		"/health status",
+		`/health\`,
+		"ftp://localhost/health",
+		"//evil.example.com/health",
+		"http:///health",
+		"/health?x=$HOME",
+	} {
+		_, err := docker.NewHealthCheck(readinessURL, "", "", "", 0, "wget")
+		assert.Error(t, err, readinessURL)
+		_, _, err = docker.ReadinessEnv(readinessURL, "")
+		assert.Error(t, err, readinessURL)
+	}
+}
@@ -9,4 +9,10 @@ const (
 	ENV_READINESS_ON_MANAGEMENT_PORT = "READINESS_ON_MANAGEMENT_PORT"
 	TZ                               = "TZ"
 	IMAGE_BUILD_TIME                 = "IMAGE_BUILD_TIME"
+	ENV_SSL_CERT_FILE                = "SSL_CERT_FILE"
+)
+
+const (
+	LABEL_BASE_IMAGE        = "www.skatteetaten.no-baseImage"
+	LABEL_BASE_IMAGE_SOURCE = "www.skatteetaten.no-baseImageSource"
 )
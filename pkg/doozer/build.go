@@ -1,22 +1,61 @@
 package doozer
 
 import (
+	"bytes"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/skatteetaten/architect/pkg/config"
 	"github.com/skatteetaten/architect/pkg/config/runtime"
 	"github.com/skatteetaten/architect/pkg/docker"
+	doozerconfig "github.com/skatteetaten/architect/pkg/doozer/config"
 	"github.com/skatteetaten/architect/pkg/doozer/prepare"
 	"github.com/skatteetaten/architect/pkg/nexus"
 	"github.com/skatteetaten/architect/pkg/process/build"
+	"github.com/skatteetaten/architect/pkg/util"
+	"regexp"
+	"strings"
 )
 
-func Prepper() process.Prepper {
+func init() {
+	process.RegisterApplicationType(process.ApplicationTypeSpec{
+		Config: config.ApplicationTypeSpec{
+			Type:           config.DoozerLeveranse,
+			Names:          []string{"doozer"},
+			Classifier:     config.Doozerleveransepakke,
+			Packaging:      config.ZipPackaging,
+			Archives:       []config.PackageType{config.ZipPackaging, config.TgzPackaging},
+			ParsePackaging: parsePackaging,
+		},
+		Name:              "Doozerleveranse",
+		Prepper:           Prepper,
+		VersionReader:     VersionReader(),
+		MetadataLoader:    MetadataLoader(),
+		MetadataValidator: MetadataValidator(),
+	})
+}
+
+var singleFilePackaging = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// PACKAGING is zip or tgz for a doozer archive, or the type of a single file, eg. jar or bin
+func parsePackaging(value string) (config.PackageType, error) {
+	packaging := strings.ToLower(value)
+	if packaging == "tar.gz" {
+		return config.TgzPackaging, nil
+	}
+	if !singleFilePackaging.MatchString(packaging) {
+		return "", errors.Errorf("Illegal value %s of PACKAGING. Use zip, tgz or the type of a single file, eg. jar", value)
+	}
+	return config.PackageType(packaging), nil
+}
+
+// The downloader is used for the sidecar metadata of a deliverable without metadata
+func Prepper(artifactDownloader nexus.Downloader) process.Prepper {
 	return func(cfg *config.Config, auroraVersion *runtime.AuroraVersion, deliverable nexus.Deliverable,
 		baseImage runtime.BaseImage) ([]docker.DockerBuildConfig, error) {
 
 		logrus.Debug("Prepare output image")
-		buildPath, err := prepare.Prepare(cfg.DockerSpec, auroraVersion, deliverable, baseImage)
+		buildPath, err := prepare.Prepare(*cfg, auroraVersion, deliverable, baseImage,
+			nexus.NewArtifactDownloader(artifactDownloader, &cfg.NexusAccess))
 
 		if err != nil {
 			return nil, errors.Wrap(err, "Error prepare artifact")
@@ -31,3 +70,74 @@ func Prepper() process.Prepper {
 		return []docker.DockerBuildConfig{buildConf}, nil
 	}
 }
+
+/*
+VersionReader reads doozer.version in the metadata, or the version in the root folder of a zip. The metadata in the
+BuildConfig is read when the deliverable has none. The sidecar metadata is not read, since it is downloaded when the
+image is prepared.
+*/
+func VersionReader() process.VersionReader {
+	return func(cfg *config.Config, deliverable nexus.Deliverable) (string, error) {
+		meta, err := loadMetadata(cfg, deliverable)
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to read application metadata")
+		}
+		if meta != nil && meta.Doozer != nil && meta.Doozer.Version != "" {
+			return meta.Doozer.Version, nil
+		}
+		if packaging := cfg.ApplicationSpec.MavenGav.Type; packaging != config.ZipPackaging && packaging != "" {
+			return "", nil
+		}
+
+		rootFolder, err := util.DeliverableRootFolder(deliverable.Path)
+		if err != nil {
+			return "", err
+		}
+		return util.DeliverableVersion(rootFolder, cfg.ApplicationSpec.MavenGav.ArtifactId), nil
+	}
+}
+
+// MetadataLoader reads the metadata in a zip, or the metadata in the BuildConfig
+func MetadataLoader() process.MetadataLoader {
+	return func(cfg *config.Config, deliverable nexus.Deliverable) (interface{}, error) {
+		meta, err := loadMetadata(cfg, deliverable)
+		if err != nil || meta == nil {
+			return nil, err
+		}
+		return meta, nil
+	}
+}
+
+// MetadataValidator checks the metadata with the same rules as when the Dockerfile is written
+func MetadataValidator() process.MetadataValidator {
+	return func(metadata interface{}) error {
+		meta, ok := metadata.(*doozerconfig.DeliverableMetadata)
+		if !ok {
+			return errors.Errorf("Unexpected doozer metadata %T", metadata)
+		}
+		return prepare.VerifyMetadata(*meta)
+	}
+}
+
+/*
+loadMetadata reads the metadata in a zip, and falls back to the metadata in the BuildConfig. Only the metadata in the
+BuildConfig is read for a tarball or a single file. It returns nil without metadata.
+*/
+func loadMetadata(cfg *config.Config, deliverable nexus.Deliverable) (*doozerconfig.DeliverableMetadata, error) {
+	var content []byte
+	if packaging := cfg.ApplicationSpec.MavenGav.Type; packaging == config.ZipPackaging || packaging == "" {
+		inDeliverable, found, err := util.FindFileInDeliverable(deliverable.Path, prepare.DeliveryMetadataPath)
+		if err != nil {
+			return nil, err
+		} else if found {
+			content = inDeliverable
+		}
+	}
+	if content == nil && cfg.ApplicationSpec.DeliverableMetadata != "" {
+		content = []byte(cfg.ApplicationSpec.DeliverableMetadata)
+	}
+	if content == nil {
+		return nil, nil
+	}
+	return doozerconfig.NewDeliverableMetadata(bytes.NewReader(content))
+}
@@ -0,0 +1,70 @@
+package doozer_test
+
+import (
+	"archive/zip"
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/doozer"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVersionReaderWithoutMetadataInTheDeliverable(t *testing.T) {
+	folder, err := ioutil.TempDir("", "doozer-version-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+	deliverable := nexus.Deliverable{Path: filepath.Join(folder, "myapp-1.2.3-DoozerLeveranse.zip")}
+	writeZip(t, deliverable.Path, "myapp-1.2.3/bin/myapp")
+
+	cfg := &config.Config{}
+	cfg.ApplicationSpec.MavenGav = config.MavenGav{ArtifactId: "myapp", Version: "1.2.3", Type: config.ZipPackaging}
+	version, err := doozer.VersionReader()(cfg, deliverable)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3", version)
+
+	cfg.ApplicationSpec.DeliverableMetadata = `{"doozer": {"version": "1.2.4"}}`
+	version, err = doozer.VersionReader()(cfg, deliverable)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.4", version)
+}
+
+func writeZip(t *testing.T, path string, files ...string) {
+	archive, err := os.Create(path)
+	assert.NoError(t, err)
+	defer archive.Close()
+	writer := zip.NewWriter(archive)
+	for _, file := range files {
+		entry, err := writer.Create(file)
+		assert.NoError(t, err)
+		_, err = entry.Write([]byte(file))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+}
+
+func TestMetadataIsValidatedBeforeTheBuild(t *testing.T) {
+	folder, err := ioutil.TempDir("", "doozer-metadata-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+	deliverable := nexus.Deliverable{Path: filepath.Join(folder, "myapp-1.2.3-DoozerLeveranse.zip")}
+	writeZip(t, deliverable.Path, "myapp-1.2.3/bin/myapp")
+
+	cfg := &config.Config{}
+	cfg.ApplicationSpec.MavenGav = config.MavenGav{ArtifactId: "myapp", Version: "1.2.3", Type: config.ZipPackaging}
+	metadata, err := doozer.MetadataLoader()(cfg, deliverable)
+	assert.NoError(t, err)
+	assert.Nil(t, metadata)
+
+	cfg.ApplicationSpec.DeliverableMetadata = `{"docker": {"maintainer": "tester"}}`
+	metadata, err = doozer.MetadataLoader()(cfg, deliverable)
+	assert.NoError(t, err)
+	assert.EqualError(t, doozer.MetadataValidator()(metadata), "Deliverable metadata does not contain \"Doozer\" element")
+
+	cfg.ApplicationSpec.DeliverableMetadata = `{"docker": {"maintainer": "tester"}, "doozer": {"srcPath": "bin/"}}`
+	metadata, err = doozer.MetadataLoader()(cfg, deliverable)
+	assert.NoError(t, err)
+	assert.NoError(t, doozer.MetadataValidator()(metadata))
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"encoding/json"
 	"github.com/pkg/errors"
+	global "github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/skatteetaten/architect/pkg/docker"
 	"io"
 	"io/ioutil"
 )
@@ -17,15 +20,52 @@ type DeliverableMetadata struct {
 type MetadataDocker struct {
 	Maintainer string            `json:"maintainer"`
 	Labels     map[string]string `json:"labels"`
+	docker.DeliverableEnv
+	// Optional. Validated by docker.NewDockerExtensions
+	Extensions map[string]json.RawMessage `json:"extensions"`
 }
 
 // TODO: Consider if "destPath" is available for fetching from base image in some way and can be optional
 type MetadataDoozer struct {
-	SrcPath      string `json:"srcPath"`
-	FileName     string `json:"fileName"`
-	DestPath     string `json:"destPath"`
-	DestFilename string `json:"destFilename"` // Optional. Will use FileName as default
-	CmdScript    string `json:"cmdScript"`    // Optional if base image CMD is applicable
+	SrcPath      string               `json:"srcPath"`
+	FileName     string               `json:"fileName"`
+	DestPath     string               `json:"destPath"`
+	DestFilename string               `json:"destFilename"` // Optional. Will use FileName as default
+	CmdScript    string               `json:"cmdScript"`    // Optional if base image CMD is applicable
+	Version      string               `json:"version"`      // Optional. The version of the file. Defaults to the version in the root folder
+	Copies       []MetadataDoozerCopy `json:"copies"`       // Optional. Copied in addition to srcPath and fileName
+	Entrypoint   []string             `json:"entrypoint"`   // Optional. ENTRYPOINT in exec form
+	Cmd          []string             `json:"cmd"`          // Optional. CMD in exec form. Cannot be combined with cmdScript
+	ExposedPorts []string             `json:"exposedPorts"` // Optional. Port numbers, optionally with /tcp or /udp
+	User         string               `json:"user"`         // Optional. Cannot be root unless Architect allows it
+	Workdir      string               `json:"workdir"`      // Optional. Absolute path
+	Volumes      []string             `json:"volumes"`      // Optional. Absolute paths
+	StopSignal   string               `json:"stopSignal"`   // Optional. Signal name like SIGTERM, or a number
+}
+
+// A file, glob or folder in the application to copy into the image
+type MetadataDoozerCopy struct {
+	SrcPath      string `json:"srcPath"`      // Relative to the application. A folder must end with /. Empty is the application
+	DestPath     string `json:"destPath"`     // Optional. Defaults to the destination path of the base image
+	DestFilename string `json:"destFilename"` // Optional. Will use the file name in srcPath as default
+	Mode         string `json:"mode"`         // Optional. Octal file mode of the copied files, eg. 0755
+	Owner        string `json:"owner"`        // Optional. User, or user and group, owning the copied files. Required with mode
+}
+
+/*
+AllCopies returns the copy in srcPath and fileName followed by the copies. Without srcPath and fileName the whole
+application is copied to destPath, or to the destination path of the base image, as before copies were added.
+*/
+func (m MetadataDoozer) AllCopies(destinationPath string) []MetadataDoozerCopy {
+	copies := make([]MetadataDoozerCopy, 0, len(m.Copies)+1)
+	if m.SrcPath != "" || m.FileName != "" || m.DestPath != "" || destinationPath != "" {
+		copies = append(copies, MetadataDoozerCopy{
+			SrcPath:      m.SrcPath + m.FileName,
+			DestPath:     m.DestPath,
+			DestFilename: m.DestFilename,
+		})
+	}
+	return append(copies, m.Copies...)
 }
 
 type MetadataJava struct {
@@ -35,8 +75,101 @@ type MetadataJava struct {
 }
 
 type MetadataOpenShift struct {
-	ReadinessURL              string `json:"readinessUrl"`
-	ReadinessOnManagementPort string `json:"readinessOnManagementPort"`
+	ReadinessURL              string               `json:"readinessUrl"`
+	ReadinessOnManagementPort string               `json:"readinessOnManagementPort"`
+	Healthcheck               *MetadataHealthcheck `json:"healthcheck"` // Optional. Adds a HEALTHCHECK polling the readiness url
+}
+
+type MetadataHealthcheck struct {
+	Interval string `json:"interval"`
+	Timeout  string `json:"timeout"`
+	Retries  int    `json:"retries"`
+}
+
+// The docker and openshift elements are shared with the python metadata, together with the functions below
+
+// VerifyDocker checks that the metadata has a docker element with a maintainer
+func VerifyDocker(meta *MetadataDocker) error {
+	if meta == nil {
+		return errors.Errorf("Deliverable metadata does not contain \"Docker\" element")
+	} else if meta.Maintainer == "" {
+		return errors.Errorf("Deliverable metadata does not contain \"Docker.Maintainer\" element")
+	}
+	return nil
+}
+
+// Readiness returns the readiness url and management port. Both are empty without an openshift element
+func (m *MetadataOpenShift) Readiness() (string, string) {
+	if m == nil {
+		return "", ""
+	}
+	return m.ReadinessURL, m.ReadinessOnManagementPort
+}
+
+// HealthCheck returns the HEALTHCHECK polling the readiness url, or nil without openshift.healthcheck
+func (m *MetadataOpenShift) HealthCheck(tool string) (*docker.HealthCheck, error) {
+	if m == nil || m.Healthcheck == nil {
+		return nil, nil
+	}
+	if m.ReadinessURL == "" {
+		return nil, errors.New("Deliverable metadata contains \"Openshift.Healthcheck\" but no readiness url")
+	}
+	return docker.NewHealthCheck(m.ReadinessURL, m.ReadinessOnManagementPort, m.Healthcheck.Interval, m.Healthcheck.Timeout,
+		m.Healthcheck.Retries, tool)
+}
+
+// ImageLabels returns the labels in the docker element and the readiness labels
+func (m MetadataDocker) ImageLabels(openshift *MetadataOpenShift) map[string]string {
+	var labels = make(map[string]string)
+
+	for k, v := range m.Labels {
+		labels[k] = v
+	}
+
+	// The readiness url is validated by ImageEnv
+	_, readinessLabels, _ := docker.ReadinessEnv(openshift.Readiness())
+	for k, v := range readinessLabels {
+		labels[k] = v
+	}
+
+	return labels
+}
+
+/*
+ImageEnv returns the env set by Architect followed by the env of the deliverable. architectEnv is set by the caller,
+like the virtualenv of python, and cannot be overridden by the deliverable either. The timezone defaults to
+docker.DefaultTimezone and the locale to defaultLocale.
+*/
+func (m MetadataDocker) ImageEnv(auroraVersion runtime.AuroraVersion, pushextratags global.PushExtraTags, imageBuildTime string,
+	openshift *MetadataOpenShift, defaultLocale string, architectEnv map[string]string) (map[string]string, error) {
+	env, _, err := docker.ReadinessEnv(openshift.Readiness())
+	if err != nil {
+		return nil, err
+	}
+	env[docker.ENV_APP_VERSION] = string(auroraVersion.GetAppVersion())
+	env[docker.ENV_AURORA_VERSION] = auroraVersion.GetCompleteVersion()
+	env[docker.ENV_PUSH_EXTRA_TAGS] = pushextratags.ToStringValue()
+	env[docker.IMAGE_BUILD_TIME] = imageBuildTime
+	for k, v := range architectEnv {
+		env[k] = v
+	}
+
+	if auroraVersion.Snapshot {
+		env[docker.ENV_SNAPSHOT_TAG] = auroraVersion.GetGivenVersion()
+	}
+
+	deliverableEnv := m.DeliverableEnv
+	if deliverableEnv.Timezone == "" {
+		deliverableEnv.Timezone = docker.DefaultTimezone
+	}
+	if deliverableEnv.Locale == "" {
+		deliverableEnv.Locale = defaultLocale
+	}
+	if err := deliverableEnv.AddTo(env); err != nil {
+		return nil, err
+	}
+
+	return env, nil
 }
 
 func NewDeliverableMetadata(reader io.Reader) (*DeliverableMetadata, error) {
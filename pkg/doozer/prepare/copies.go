@@ -0,0 +1,148 @@
+package prepare
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/docker"
+	"github.com/skatteetaten/architect/pkg/doozer/config"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// A copy from the application to the image, as rendered in the Dockerfile
+type dockerfileCopy struct {
+	Source      string
+	Destination string
+	Owner       string
+	// The command setting the file mode of the copied files. Empty if the mode is not set
+	Chmod string
+}
+
+var fileMode = regexp.MustCompile(`^0?[0-7]{3}$`)
+
+func isGlob(srcPath string) bool {
+	return strings.ContainsAny(srcPath, "*?[")
+}
+
+// An empty srcPath is the application folder
+func isFolder(srcPath string) bool {
+	return srcPath == "" || strings.HasSuffix(srcPath, "/")
+}
+
+func validatePath(value string) error {
+	if value == "" || strings.ContainsAny(value, " \t\r\n\"'\\") {
+		return errors.Errorf("Illegal path %q. Whitespace, quotes and backslashes are not allowed", value)
+	}
+	return nil
+}
+
+// createCopies validates the copies in the doozer metadata. The destination path of the base image is used for copies
+// without destPath
+func createCopies(doozer *config.MetadataDoozer, destinationPath string) ([]dockerfileCopy, error) {
+	copies := make([]dockerfileCopy, 0, len(doozer.Copies)+1)
+	for _, metaCopy := range doozer.AllCopies(destinationPath) {
+		if metaCopy.SrcPath != "" {
+			if err := validatePath(metaCopy.SrcPath); err != nil {
+				return nil, errors.Wrap(err, "Illegal srcPath in doozer metadata")
+			}
+		}
+		if strings.HasPrefix(metaCopy.SrcPath, "/") || strings.HasPrefix(path.Clean(metaCopy.SrcPath), "..") {
+			return nil, errors.Errorf("Illegal srcPath %s in doozer metadata. Use a path relative to the application", metaCopy.SrcPath)
+		}
+
+		destPath := metaCopy.DestPath
+		if destPath == "" {
+			destPath = destinationPath
+		}
+		if destPath == "" {
+			return nil, errors.Errorf("No destPath for %s in doozer metadata, and the base image has no destination path", metaCopy.SrcPath)
+		}
+		if err := validatePath(destPath); err != nil {
+			return nil, errors.Wrap(err, "Illegal destPath in doozer metadata")
+		}
+		if metaCopy.Owner != "" {
+			if err := docker.ValidateUser(metaCopy.Owner, true); err != nil {
+				return nil, errors.Wrap(err, "Illegal owner in doozer metadata")
+			}
+		}
+		if metaCopy.Mode != "" && !fileMode.MatchString(metaCopy.Mode) {
+			return nil, errors.Errorf("Illegal mode %s in doozer metadata. Use an octal file mode, eg. 0755", metaCopy.Mode)
+		}
+		// COPY makes root the owner, and the mode is set by the user of the base image
+		if metaCopy.Mode != "" && metaCopy.Owner == "" {
+			return nil, errors.Errorf("Mode %s of %s in doozer metadata requires an owner that can change it", metaCopy.Mode, metaCopy.SrcPath)
+		}
+
+		dockerCopy := dockerfileCopy{
+			Source: metaCopy.SrcPath,
+			Owner:  metaCopy.Owner,
+		}
+		var modeTarget string
+		switch {
+		case isFolder(metaCopy.SrcPath) || isGlob(metaCopy.SrcPath):
+			if metaCopy.DestFilename != "" {
+				return nil, errors.Errorf("destFilename cannot be used when copying the folder or glob %s in doozer metadata", metaCopy.SrcPath)
+			}
+			if !strings.HasSuffix(destPath, "/") {
+				destPath += "/"
+			}
+			dockerCopy.Destination = destPath
+			modeTarget = destPath + path.Base(metaCopy.SrcPath)
+		case strings.HasSuffix(destPath, "/"):
+			destFilename := metaCopy.DestFilename
+			if destFilename == "" {
+				destFilename = path.Base(metaCopy.SrcPath)
+			}
+			dockerCopy.Destination = destPath + destFilename
+			modeTarget = dockerCopy.Destination
+		default:
+			// The destination is the file itself
+			if metaCopy.DestFilename != "" {
+				dockerCopy.Destination = path.Join(destPath, metaCopy.DestFilename)
+			} else {
+				dockerCopy.Destination = destPath
+			}
+			modeTarget = dockerCopy.Destination
+		}
+
+		if metaCopy.Mode != "" {
+			if isFolder(metaCopy.SrcPath) {
+				dockerCopy.Chmod = fmt.Sprintf("find %s -type f -exec chmod %s {} +", dockerCopy.Destination, metaCopy.Mode)
+			} else {
+				dockerCopy.Chmod = fmt.Sprintf("chmod %s %s", metaCopy.Mode, modeTarget)
+			}
+		}
+		copies = append(copies, dockerCopy)
+	}
+	return copies, nil
+}
+
+// verifyCopySources checks that the sources of the copies exist in the application
+func verifyCopySources(doozer *config.MetadataDoozer, applicationFolder string) error {
+	// The copy of the whole application needs no verification
+	for _, metaCopy := range doozer.AllCopies("") {
+		source := filepath.Join(applicationFolder, filepath.FromSlash(metaCopy.SrcPath))
+		matches, err := filepath.Glob(source)
+		if err != nil {
+			return errors.Wrapf(err, "Illegal srcPath %s in doozer metadata", metaCopy.SrcPath)
+		}
+		if len(matches) == 0 {
+			return errors.Errorf("srcPath %s in doozer metadata matches no files in the deliverable", metaCopy.SrcPath)
+		}
+		if isGlob(metaCopy.SrcPath) {
+			continue
+		}
+		info, err := os.Stat(matches[0])
+		if err != nil {
+			return errors.Wrapf(err, "Failed to read %s in the deliverable", metaCopy.SrcPath)
+		}
+		// The mode is set on the wrong path if a folder is taken for a file
+		if info.IsDir() && !isFolder(metaCopy.SrcPath) && metaCopy.Mode != "" {
+			return errors.Errorf("srcPath %s in doozer metadata is a folder. End it with / to set the mode", metaCopy.SrcPath)
+		}
+	}
+	return nil
+}
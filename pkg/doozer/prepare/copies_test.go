@@ -0,0 +1,36 @@
+package prepare
+
+import (
+	"github.com/skatteetaten/architect/pkg/doozer/config"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyCopySources(t *testing.T) {
+	applicationFolder, err := ioutil.TempDir("", "application")
+	assert.NoError(t, err)
+	defer os.RemoveAll(applicationFolder)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(applicationFolder, "scripts"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(applicationFolder, "scripts", "run.sh"), []byte("#!/bin/sh"), 0644))
+
+	assert.NoError(t, verifyCopySources(&config.MetadataDoozer{
+		SrcPath:  "scripts/",
+		FileName: "run.sh",
+		Copies: []config.MetadataDoozerCopy{
+			{SrcPath: "scripts/*.sh"},
+			{SrcPath: "scripts/", Mode: "0755"},
+		},
+	}, applicationFolder))
+
+	assert.EqualError(t, verifyCopySources(&config.MetadataDoozer{
+		Copies: []config.MetadataDoozerCopy{{SrcPath: "bin/*"}},
+	}, applicationFolder), "srcPath bin/* in doozer metadata matches no files in the deliverable")
+
+	assert.EqualError(t, verifyCopySources(&config.MetadataDoozer{
+		Copies: []config.MetadataDoozerCopy{{SrcPath: "scripts", Mode: "0755"}},
+	}, applicationFolder), "srcPath scripts in doozer metadata is a folder. End it with / to set the mode")
+}
@@ -0,0 +1,66 @@
+package prepare
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/skatteetaten/architect/pkg/util"
+	"path/filepath"
+)
+
+/*
+A doozer deliverable is a zip or a gzipped tarball, or a single file like a static binary or a jar. It is put in the
+application folder:
+
+	zip and tgz   the content of the archive, without its root folder
+	single file   <artifactId>.<packaging>, eg. myapp.jar
+
+The metadata is metadata/openshift.json in the archive. Without it, DELIVERABLE_METADATA in the BuildConfig or the
+openshift.json deployed with classifier openshift next to the deliverable in Nexus is used.
+*/
+func prepareDeliverable(cfg config.Config, deliverable nexus.Deliverable, dockerBuildPath string, downloadArtifact nexus.ArtifactDownloader) error {
+	applicationFolder := filepath.Join(dockerBuildPath, util.ApplicationBuildFolder)
+
+	packaging := cfg.ApplicationSpec.MavenGav.Type
+	switch packaging {
+	case config.ZipPackaging, "":
+		if err := util.ExtractAndRenameDeliverable(dockerBuildPath, deliverable.Path); err != nil {
+			return errors.Wrap(err, "Failed to extract application archive")
+		}
+	case config.TgzPackaging:
+		if err := util.ExtractTgzDeliverable(dockerBuildPath, deliverable.Path); err != nil {
+			return err
+		}
+	default:
+		fileName := singleFileName(cfg.ApplicationSpec.MavenGav, deliverable)
+		logrus.Infof("Using the single file deliverable as %s", fileName)
+		if err := util.CopyFile(deliverable.Path, filepath.Join(applicationFolder, fileName)); err != nil {
+			return errors.Wrap(err, "Failed to copy the deliverable")
+		}
+	}
+
+	metadataExists, err := util.Exists(filepath.Join(applicationFolder, DeliveryMetadataPath))
+	if err != nil {
+		return errors.Wrap(err, "Failed to read application metadata")
+	} else if metadataExists {
+		return nil
+	}
+
+	metadata, err := nexus.FindSidecarMetadata(cfg, downloadArtifact)
+	if err != nil {
+		return err
+	}
+	if err := util.NewFileWriter(applicationFolder)(util.NewByteWriter(metadata), DeliveryMetadataPath); err != nil {
+		return errors.Wrap(err, "Failed to write application metadata")
+	}
+	return nil
+}
+
+// The name of a single file deliverable in the application folder
+func singleFileName(gav config.MavenGav, deliverable nexus.Deliverable) string {
+	if gav.ArtifactId == "" {
+		return filepath.Base(deliverable.Path)
+	}
+	return gav.ArtifactId + "." + string(gav.Type)
+}
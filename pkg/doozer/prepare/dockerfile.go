@@ -7,75 +7,53 @@ import (
 	"github.com/skatteetaten/architect/pkg/config/runtime"
 	"github.com/skatteetaten/architect/pkg/docker"
 	"github.com/skatteetaten/architect/pkg/doozer/config"
+	"github.com/skatteetaten/architect/pkg/templates"
 	"github.com/skatteetaten/architect/pkg/util"
 	"io"
 )
 
-var dockerFileTemplateBody string = `FROM {{.BaseImage}}
-
-MAINTAINER {{.Maintainer}}
-LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
-
-# temp env hack until standard base image is available
-ENV LANG='en_US.UTF-8' \
-    TZ=Europe/Oslo \
-    HOME=/u01
-
-COPY ./app radish.json $HOME/
-COPY ./app/application/{{.SrcPath}}{{.FileName}} {{.DestPath}}
-
-RUN find $HOME/application -type d -exec chmod 755 {} + && \
-	find $HOME/application -type f -exec chmod 644 {} + && \
-	mkdir -p $HOME/logs && \
-	chmod 777 $HOME/logs && \
-	ln -s $HOME/logs $HOME/application/logs
-
-ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
-`
-var dockerFileTemplateCmd string = `CMD "{{.CmdScript}}"
-`
-
 type DockerfileData struct {
-	BaseImage  string
-	Maintainer string
-	SrcPath    string
-	FileName   string
-	DestPath   string
-	CmdScript  string
-	Labels     map[string]string
-	Env        map[string]string
+	BaseImage   string
+	Home        string
+	Maintainer  string
+	Copies      []dockerfileCopy
+	CmdScript   string
+	Labels      map[string]string
+	Env         map[string]string
+	HealthCheck *docker.HealthCheck
+	Extensions  string
+	// WORKDIR, EXPOSE, VOLUME, STOPSIGNAL, USER, ENTRYPOINT and CMD from the doozer metadata
+	Runtime string
+	// The single copy of the legacy templates
+	SrcPath  string
+	FileName string
+	DestPath string
 }
 
-func createEnv(auroraVersion runtime.AuroraVersion, pushextratags global.PushExtraTags, imageBuildTime string) map[string]string {
-	env := make(map[string]string)
-	env[docker.ENV_APP_VERSION] = string(auroraVersion.GetAppVersion())
-	env[docker.ENV_AURORA_VERSION] = auroraVersion.GetCompleteVersion()
-	env[docker.ENV_PUSH_EXTRA_TAGS] = pushextratags.ToStringValue()
-	env[docker.TZ] = "Europe/Oslo"
-	env[docker.IMAGE_BUILD_TIME] = imageBuildTime
-
-	if auroraVersion.Snapshot {
-		env[docker.ENV_SNAPSHOT_TAG] = auroraVersion.GetGivenVersion()
+// The legacy templates copy srcPath and fileName, and have none of the instructions added later
+func verifyTemplateVersion(templateSet *templates.TemplateSet, doozer *config.MetadataDoozer, data *DockerfileData) error {
+	if !templateSet.Legacy() {
+		return nil
 	}
-
-	return env
-}
-
-func createLabels(meta config.DeliverableMetadata) map[string]string {
-	var labels = make(map[string]string)
-
-	for k, v := range meta.Docker.Labels {
-		labels[k] = v
+	if len(doozer.Copies) > 0 {
+		return templates.LegacyError("Doozer.Copies in the deliverable metadata")
 	}
-
-	return labels
+	if data.Runtime != "" {
+		return templates.LegacyError("Runtime instructions in the doozer metadata")
+	}
+	if data.HealthCheck != nil {
+		return templates.LegacyError("Openshift.Healthcheck in the deliverable metadata")
+	}
+	if data.Extensions != "" {
+		return templates.LegacyError("Docker.Extensions in the deliverable metadata")
+	}
+	return nil
 }
 
-func verifyMetadata(meta config.DeliverableMetadata) error {
-	if meta.Docker == nil {
-		return errors.Errorf("Deliverable metadata does not contain \"Docker\" element")
-	} else if meta.Docker.Maintainer == "" {
-		return errors.Errorf("Deliverable metadata does not contain \"Docker.Maintainer\" element")
+// VerifyMetadata is also the metadata validator of the doozer application type
+func VerifyMetadata(meta config.DeliverableMetadata) error {
+	if err := config.VerifyDocker(meta.Docker); err != nil {
+		return err
 	}
 	if meta.Doozer == nil {
 		return errors.Errorf("Deliverable metadata does not contain \"Doozer\" element")
@@ -84,46 +62,90 @@ func verifyMetadata(meta config.DeliverableMetadata) error {
 	return nil
 }
 
+// The locale of a doozer image when neither the deliverable nor the base image has one
+const DefaultLocale = "en_US.UTF-8"
+
+// home is HOME in the base image. It defaults to /u01
 func NewDockerFile(dockerSpec global.DockerSpec, auroraVersion runtime.AuroraVersion, meta config.DeliverableMetadata,
-	baseImage runtime.DockerImage, imageBuildTime string, destinationPath string) util.WriterFunc {
+	baseImage runtime.DockerImage, imageBuildTime string, destinationPath string, home string) util.WriterFunc {
 	return func(writer io.Writer) error {
-		if err := verifyMetadata(meta); err != nil {
+		if err := VerifyMetadata(meta); err != nil {
 			return err
 		}
-		env := createEnv(auroraVersion, dockerSpec.PushExtraTags, imageBuildTime)
+		env, err := meta.Docker.ImageEnv(auroraVersion, dockerSpec.PushExtraTags, imageBuildTime, meta.Openshift, DefaultLocale, nil)
+		if err != nil {
+			return err
+		}
+		healthCheck, err := meta.Openshift.HealthCheck(dockerSpec.HealthCheckTool)
+		if err != nil {
+			return err
+		}
+		extensions, err := docker.NewDockerExtensions(meta.Docker.Extensions)
+		if err != nil {
+			return err
+		}
+		if err := rejectRuntimeExtensions(extensions); err != nil {
+			return err
+		}
+		labels := meta.Docker.ImageLabels(meta.Openshift)
+		extensions.AddLabels(labels)
 
-		dockerFileTemplate := dockerFileTemplateBody
+		dockerFileTemplate, err := dockerSpec.Templates.Get(templates.DoozerDockerfile)
+		if err != nil {
+			return err
+		}
 		if meta.Doozer.CmdScript != "" {
+			dockerFileTemplateCmd, err := dockerSpec.Templates.Get(templates.DoozerDockerfileCmd)
+			if err != nil {
+				return err
+			}
 			dockerFileTemplate += dockerFileTemplateCmd
 		}
 
-		var destPath string
-
 		if meta.Doozer.DestPath != "" {
-			destPath = meta.Doozer.DestPath
-			if meta.Doozer.DestPath != "" {
-				logrus.Warnf("The destination path is overridden by provided metadata: %s", meta.Doozer.DestPath)
-			}
-			logrus.Debugf("Using destination path %s  from metadata", destinationPath)
-		} else {
-			destPath = destinationPath
+			logrus.Warnf("The destination path is overridden by provided metadata: %s", meta.Doozer.DestPath)
+		}
+		copies, err := createCopies(meta.Doozer, destinationPath)
+		if err != nil {
+			return err
+		}
+		runtimeInstructions, err := createRuntimeInstructions(meta.Doozer, dockerSpec.AllowRootUser)
+		if err != nil {
+			return err
 		}
 
-		if meta.Doozer.FileName != "" {
-			destPath += meta.Doozer.FileName
+		if home == "" {
+			home = util.DockerBasedir
 		}
 
 		data := &DockerfileData{
-			BaseImage:  baseImage.GetCompleteDockerTagName(),
-			Maintainer: meta.Docker.Maintainer,
-			SrcPath:    meta.Doozer.SrcPath,
-			FileName:   meta.Doozer.FileName,
-			DestPath:   destPath,
-			CmdScript:  meta.Doozer.CmdScript,
-			Labels:     createLabels(meta),
-			Env:        env,
+			BaseImage:   baseImage.GetCompleteDockerTagName(),
+			Home:        home,
+			Maintainer:  meta.Docker.Maintainer,
+			Copies:      copies,
+			CmdScript:   meta.Doozer.CmdScript,
+			Labels:      labels,
+			Env:         env,
+			HealthCheck: healthCheck,
+			Extensions:  extensions.Instructions(),
+			Runtime:     runtimeInstructions,
+			SrcPath:     meta.Doozer.SrcPath,
+			FileName:    meta.Doozer.FileName,
+			DestPath:    legacyDestPath(meta.Doozer, destinationPath),
+		}
+		if err := verifyTemplateVersion(dockerSpec.Templates, meta.Doozer, data); err != nil {
+			return err
 		}
 
 		return util.NewTemplateWriter(data, "Dockerfile", dockerFileTemplate)(writer)
 	}
 }
+
+// The destination of the legacy templates. destFilename is not used by them
+func legacyDestPath(doozer *config.MetadataDoozer, destinationPath string) string {
+	destPath := destinationPath
+	if doozer.DestPath != "" {
+		destPath = doozer.DestPath
+	}
+	return destPath + doozer.FileName
+}
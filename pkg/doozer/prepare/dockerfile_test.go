@@ -2,10 +2,12 @@ package prepare_test
 
 import (
 	"bytes"
+	"encoding/json"
 	global "github.com/skatteetaten/architect/pkg/config"
 	"github.com/skatteetaten/architect/pkg/config/runtime"
 	"github.com/skatteetaten/architect/pkg/doozer/config"
 	"github.com/skatteetaten/architect/pkg/doozer/prepare"
+	"github.com/skatteetaten/architect/pkg/templates"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -15,10 +17,7 @@ const expectedDockerfile = `FROM tomcat:8.5.47-jdk11-openjdk
 MAINTAINER maintain@me.no
 LABEL maintainer="maintain.me.no" no.skatteetaten.test="TestLabel" randomlabel="Use the 4ce"
 
-# temp env hack until standard base image is available
-ENV LANG='en_US.UTF-8' \
-    TZ=Europe/Oslo \
-    HOME=/u01
+ENV HOME=/u01
 
 COPY ./app radish.json $HOME/
 COPY ./app/application/app/uberfile.war /path/to/your/destiny/uberfile.war
@@ -29,7 +28,7 @@ RUN find $HOME/application -type d -exec chmod 755 {} + && \
 	chmod 777 $HOME/logs && \
 	ln -s $HOME/logs $HOME/application/logs
 
-ENV APP_VERSION="0.0.1-SNAPSHOT" AURORA_VERSION="0.0.1-SNAPSHOT-bbuildimage-tomcat-8.5.47-jdk11-openjdk" IMAGE_BUILD_TIME="2017-09-10T14:30:10Z" PUSH_EXTRA_TAGS="major" SNAPSHOT_TAG="0.0.1-SNAPSHOT" TZ="Europe/Oslo"
+ENV APP_VERSION="0.0.1-SNAPSHOT" AURORA_VERSION="0.0.1-SNAPSHOT-bbuildimage-tomcat-8.5.47-jdk11-openjdk" IMAGE_BUILD_TIME="2017-09-10T14:30:10Z" LANG="en_US.UTF-8" PUSH_EXTRA_TAGS="major" SNAPSHOT_TAG="0.0.1-SNAPSHOT" TZ="Europe/Oslo"
 `
 
 const expectedDockerfileWithCmdScript = `FROM builder:latest
@@ -37,10 +36,7 @@ const expectedDockerfileWithCmdScript = `FROM builder:latest
 MAINTAINER maintain@me.no
 LABEL maintainer="maintain.me.no" no.skatteetaten.test="TestLabel" randomlabel="Use the 4ce"
 
-# temp env hack until standard base image is available
-ENV LANG='en_US.UTF-8' \
-    TZ=Europe/Oslo \
-    HOME=/u01
+ENV HOME=/u01
 
 COPY ./app radish.json $HOME/
 COPY ./app/application/app/uberfile.war /path/to/your/destiny/uberfile.war
@@ -51,12 +47,13 @@ RUN find $HOME/application -type d -exec chmod 755 {} + && \
 	chmod 777 $HOME/logs && \
 	ln -s $HOME/logs $HOME/application/logs
 
-ENV APP_VERSION="0.0.1-SNAPSHOT" AURORA_VERSION="0.0.1-SNAPSHOT-bbuildimage-builder-latest" IMAGE_BUILD_TIME="2017-09-10T14:30:10Z" PUSH_EXTRA_TAGS="major" SNAPSHOT_TAG="0.0.1-SNAPSHOT" TZ="Europe/Oslo"
+ENV APP_VERSION="0.0.1-SNAPSHOT" AURORA_VERSION="0.0.1-SNAPSHOT-bbuildimage-builder-latest" IMAGE_BUILD_TIME="2017-09-10T14:30:10Z" LANG="en_US.UTF-8" PUSH_EXTRA_TAGS="major" SNAPSHOT_TAG="0.0.1-SNAPSHOT" TZ="Europe/Oslo"
 CMD "./bin/somestartupcmd"
 `
 
 func TestBuild(t *testing.T) {
 	dockerSpec := global.DockerSpec{
+		Templates:     templatesVersion2(t),
 		PushExtraTags: global.ParseExtraTags("major"),
 	}
 	baseImage := runtime.DockerImage{
@@ -87,7 +84,7 @@ func TestBuild(t *testing.T) {
 			DestPath: "/path/to/your/destiny/",
 		},
 	}
-	writer := prepare.NewDockerFile(dockerSpec, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "")
+	writer := prepare.NewDockerFile(dockerSpec, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "", "")
 
 	buffer := new(bytes.Buffer)
 
@@ -98,6 +95,7 @@ func TestBuild(t *testing.T) {
 
 func TestBuildWithCmdScript(t *testing.T) {
 	dockerSpec := global.DockerSpec{
+		Templates:     templatesVersion2(t),
 		PushExtraTags: global.ParseExtraTags("major"),
 	}
 	baseImage := runtime.DockerImage{
@@ -129,7 +127,7 @@ func TestBuildWithCmdScript(t *testing.T) {
 			CmdScript: "./bin/somestartupcmd",
 		},
 	}
-	writer := prepare.NewDockerFile(dockerSpec, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "")
+	writer := prepare.NewDockerFile(dockerSpec, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "", "")
 
 	buffer := new(bytes.Buffer)
 
@@ -137,3 +135,226 @@ func TestBuildWithCmdScript(t *testing.T) {
 	assert.Equal(t, expectedDockerfileWithCmdScript, buffer.String())
 
 }
+
+func TestBuildWithCopies(t *testing.T) {
+	baseImage := runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "builder",
+	}
+	auroraVersions := runtime.NewAuroraVersionFromBuilderAndBase("1.0.0", false, "1.0.0",
+		&runtime.ArchitectImage{Tag: "buildimage"}, baseImage)
+	deliverableMetadata := config.DeliverableMetadata{
+		Docker: &config.MetadataDocker{
+			Maintainer: "maintain@me.no",
+		},
+		Doozer: &config.MetadataDoozer{
+			SrcPath:      "bin/",
+			FileName:     "architect",
+			DestFilename: "architect-cli",
+			Copies: []config.MetadataDoozerCopy{
+				{SrcPath: "scripts/*.sh", Mode: "0755", Owner: "1001:0"},
+				{SrcPath: "conf/", DestPath: "/etc/architect", Mode: "0644", Owner: "1001"},
+				{SrcPath: "lib/libfoo.so", DestPath: "/usr/lib/libfoo.so.1"},
+			},
+		},
+	}
+	writer := prepare.NewDockerFile(global.DockerSpec{Templates: templatesVersion2(t)}, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "/u01/bin/", "")
+
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, writer(buffer))
+	assert.Contains(t, buffer.String(), `COPY ./app radish.json $HOME/
+COPY ./app/application/bin/architect /u01/bin/architect-cli
+COPY --chown=1001:0 ./app/application/scripts/*.sh /u01/bin/
+COPY --chown=1001 ./app/application/conf/ /etc/architect/
+COPY ./app/application/lib/libfoo.so /usr/lib/libfoo.so.1
+
+RUN find $HOME/application -type d -exec chmod 755 {} + && \
+	find $HOME/application -type f -exec chmod 644 {} + && \
+	mkdir -p $HOME/logs && \
+	chmod 777 $HOME/logs && \
+	ln -s $HOME/logs $HOME/application/logs && \
+	chmod 0755 /u01/bin/*.sh && \
+	find /etc/architect/ -type f -exec chmod 0644 {} +
+`)
+}
+
+func TestBuildCopiesTheApplicationWithoutSrcPath(t *testing.T) {
+	baseImage := runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "builder",
+	}
+	auroraVersions := runtime.NewAuroraVersionFromBuilderAndBase("1.0.0", false, "1.0.0",
+		&runtime.ArchitectImage{Tag: "buildimage"}, baseImage)
+	deliverableMetadata := config.DeliverableMetadata{
+		Docker: &config.MetadataDocker{Maintainer: "maintain@me.no"},
+		Doozer: &config.MetadataDoozer{CmdScript: "/u01/bin/run"},
+	}
+
+	writer := prepare.NewDockerFile(global.DockerSpec{Templates: templatesVersion2(t)}, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "/u01/bin", "")
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, writer(buffer))
+	assert.Contains(t, buffer.String(), "COPY ./app radish.json $HOME/\nCOPY ./app/application/ /u01/bin/\n")
+
+	deliverableMetadata.Doozer = &config.MetadataDoozer{CmdScript: "/u01/bin/run"}
+	writer = prepare.NewDockerFile(global.DockerSpec{Templates: templatesVersion2(t)}, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "", "")
+	buffer = new(bytes.Buffer)
+	assert.NoError(t, writer(buffer))
+	assert.Contains(t, buffer.String(), "COPY ./app radish.json $HOME/\n\nRUN")
+}
+
+func TestBuildWithIllegalCopies(t *testing.T) {
+	baseImage := runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "builder",
+	}
+	auroraVersions := runtime.NewAuroraVersionFromBuilderAndBase("1.0.0", false, "1.0.0",
+		&runtime.ArchitectImage{Tag: "buildimage"}, baseImage)
+	for expected, metaCopy := range map[string]config.MetadataDoozerCopy{
+		"Illegal srcPath ../secret in doozer metadata. Use a path relative to the application":                                  {SrcPath: "../secret", DestPath: "/u01/"},
+		"No destPath for app.jar in doozer metadata, and the base image has no destination path":                                {SrcPath: "app.jar"},
+		"Illegal mode 999 in doozer metadata. Use an octal file mode, eg. 0755":                                                 {SrcPath: "app.jar", DestPath: "/u01/", Mode: "999"},
+		"Illegal owner in doozer metadata: Illegal user root;. Use a user name or id, optionally with a group":                  {SrcPath: "app.jar", DestPath: "/u01/", Owner: "root;"},
+		"Mode 0755 of app.jar in doozer metadata requires an owner that can change it":                                          {SrcPath: "app.jar", DestPath: "/u01/", Mode: "0755"},
+		"destFilename cannot be used when copying the folder or glob lib/*.jar in doozer metadata":                              {SrcPath: "lib/*.jar", DestPath: "/u01/", DestFilename: "app.jar"},
+		"Illegal destPath in doozer metadata: Illegal path \"/u01/my app\". Whitespace, quotes and backslashes are not allowed": {SrcPath: "app.jar", DestPath: "/u01/my app"},
+	} {
+		deliverableMetadata := config.DeliverableMetadata{
+			Docker: &config.MetadataDocker{Maintainer: "maintain@me.no"},
+			Doozer: &config.MetadataDoozer{Copies: []config.MetadataDoozerCopy{metaCopy}},
+		}
+		writer := prepare.NewDockerFile(global.DockerSpec{Templates: templatesVersion2(t)}, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "", "")
+		assert.EqualError(t, writer(new(bytes.Buffer)), expected)
+	}
+}
+
+func TestBuildWithRuntimeMetadata(t *testing.T) {
+	baseImage := runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "builder",
+	}
+	auroraVersions := runtime.NewAuroraVersionFromBuilderAndBase("1.0.0", false, "1.0.0",
+		&runtime.ArchitectImage{Tag: "buildimage"}, baseImage)
+	deliverableMetadata := config.DeliverableMetadata{
+		Docker: &config.MetadataDocker{
+			Maintainer: "maintain@me.no",
+		},
+		Doozer: &config.MetadataDoozer{
+			SrcPath:      "bin/",
+			FileName:     "server",
+			DestPath:     "/u01/bin/",
+			Entrypoint:   []string{"/u01/bin/server"},
+			Cmd:          []string{"--config", "/u01/config/server.yaml"},
+			ExposedPorts: []string{"8080", "9090/udp"},
+			User:         "1001:0",
+			Workdir:      "/u01",
+			Volumes:      []string{"/u01/data"},
+			StopSignal:   "SIGINT",
+		},
+	}
+	writer := prepare.NewDockerFile(global.DockerSpec{Templates: templatesVersion2(t)}, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "", "")
+
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, writer(buffer))
+	assert.Contains(t, buffer.String(), `TZ="Europe/Oslo"
+WORKDIR /u01
+EXPOSE 8080
+EXPOSE 9090/udp
+VOLUME ["/u01/data"]
+STOPSIGNAL SIGINT
+USER 1001:0
+ENTRYPOINT ["/u01/bin/server"]
+CMD ["--config","/u01/config/server.yaml"]
+`)
+}
+
+func TestBuildWithIllegalRuntimeMetadata(t *testing.T) {
+	baseImage := runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "builder",
+	}
+	auroraVersions := runtime.NewAuroraVersionFromBuilderAndBase("1.0.0", false, "1.0.0",
+		&runtime.ArchitectImage{Tag: "buildimage"}, baseImage)
+	for expected, doozer := range map[string]config.MetadataDoozer{
+		"Illegal port http in doozer metadata. Use a port number, optionally with /tcp or /udp": {ExposedPorts: []string{"http"}},
+		"Illegal port 0 in doozer metadata. Use a port between 1 and 65535":                     {ExposedPorts: []string{"0"}},
+		"Illegal user in doozer metadata: The image cannot run as root":                         {User: "root"},
+		"Illegal workdir in doozer metadata: u01 is not an absolute path":                       {Workdir: "u01"},
+		"Illegal volume in doozer metadata: /u01/../etc is not an absolute path":                {Volumes: []string{"/u01/../etc"}},
+		"Doozer metadata cannot contain both cmd and cmdScript":                                 {Cmd: []string{"run"}, CmdScript: "/u01/bin/run"},
+	} {
+		doozer := doozer
+		deliverableMetadata := config.DeliverableMetadata{
+			Docker: &config.MetadataDocker{Maintainer: "maintain@me.no"},
+			Doozer: &doozer,
+		}
+		writer := prepare.NewDockerFile(global.DockerSpec{Templates: templatesVersion2(t)}, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "", "")
+		assert.EqualError(t, writer(new(bytes.Buffer)), expected)
+	}
+
+	deliverableMetadata := config.DeliverableMetadata{
+		Docker: &config.MetadataDocker{Maintainer: "maintain@me.no"},
+		Doozer: &config.MetadataDoozer{User: "0"},
+	}
+	writer := prepare.NewDockerFile(global.DockerSpec{AllowRootUser: true, Templates: templatesVersion2(t)}, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "", "")
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, writer(buffer))
+	assert.Contains(t, buffer.String(), "\nUSER 0\n")
+
+	deliverableMetadata = config.DeliverableMetadata{
+		Docker: &config.MetadataDocker{
+			Maintainer: "maintain@me.no",
+			Extensions: map[string]json.RawMessage{"user": json.RawMessage(`"1001"`)},
+		},
+		Doozer: &config.MetadataDoozer{User: "1002"},
+	}
+	writer = prepare.NewDockerFile(global.DockerSpec{Templates: templatesVersion2(t)}, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "", "")
+	assert.EqualError(t, writer(new(bytes.Buffer)), "Set user, exposedPorts and volumes in the doozer metadata, not in docker.extensions")
+}
+
+func TestBuildWithLegacyTemplates(t *testing.T) {
+	baseImage := runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "builder",
+	}
+	auroraVersions := runtime.NewAuroraVersionFromBuilderAndBase("1.0.0", false, "1.0.0",
+		&runtime.ArchitectImage{Tag: "buildimage"}, baseImage)
+	deliverableMetadata := config.DeliverableMetadata{
+		Docker: &config.MetadataDocker{
+			Maintainer: "maintain@me.no",
+		},
+		Doozer: &config.MetadataDoozer{
+			SrcPath:  "app/",
+			FileName: "uberfile.war",
+		},
+	}
+	writer := prepare.NewDockerFile(global.DockerSpec{}, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "/u01/bin/", "")
+
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, writer(buffer))
+	assert.Contains(t, buffer.String(), `# temp env hack until standard base image is available
+ENV LANG='en_US.UTF-8' \
+    TZ=Europe/Oslo \
+    HOME=/u01
+
+COPY ./app radish.json $HOME/
+COPY ./app/application/app/uberfile.war /u01/bin/uberfile.war
+
+RUN find $HOME/application -type d -exec chmod 755 {} + && \
+	find $HOME/application -type f -exec chmod 644 {} + && \
+	mkdir -p $HOME/logs && \
+	chmod 777 $HOME/logs && \
+	ln -s $HOME/logs $HOME/application/logs
+`)
+
+	deliverableMetadata.Doozer.Copies = []config.MetadataDoozerCopy{{SrcPath: "lib/libfoo.so", DestPath: "/usr/lib/"}}
+	writer = prepare.NewDockerFile(global.DockerSpec{}, *auroraVersions, deliverableMetadata, baseImage, "2017-09-10T14:30:10Z", "/u01/bin/", "")
+	assert.EqualError(t, writer(new(bytes.Buffer)),
+		"Doozer.Copies in the deliverable metadata is not supported by template version 1. Use a base image with template version 2")
+}
+
+// The base images with template version 2 support copies, runtime instructions, healthchecks and extensions
+func templatesVersion2(t *testing.T) *templates.TemplateSet {
+	templateSet, err := templates.Load("", "2")
+	assert.NoError(t, err)
+	return templateSet
+}
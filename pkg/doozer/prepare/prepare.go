@@ -8,6 +8,7 @@ import (
 	"github.com/skatteetaten/architect/pkg/docker"
 	deliverable "github.com/skatteetaten/architect/pkg/doozer/config"
 	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/skatteetaten/architect/pkg/templates"
 	"github.com/skatteetaten/architect/pkg/util"
 	"io"
 	"io/ioutil"
@@ -24,7 +25,10 @@ type FileGenerator interface {
 	Write(writer io.Writer) error
 }
 
-func Prepare(dockerSpec config.DockerSpec, auroraVersions *runtime.AuroraVersion, deliverable nexus.Deliverable, baseImage runtime.BaseImage) (string, error) {
+func Prepare(cfg config.Config, auroraVersions *runtime.AuroraVersion, deliverable nexus.Deliverable, baseImage runtime.BaseImage,
+	downloadArtifact nexus.ArtifactDownloader) (string, error) {
+
+	dockerSpec := cfg.DockerSpec
 
 	// Create docker build folder
 	dockerBuildPath, err := ioutil.TempDir("", "deliverable")
@@ -33,12 +37,12 @@ func Prepare(dockerSpec config.DockerSpec, auroraVersions *runtime.AuroraVersion
 		return "", errors.Wrap(err, "Failed to create root folder of Docker context")
 	}
 
-	// Unzip deliverable
+	// Extract or copy deliverable
 	applicationFolder := filepath.Join(dockerBuildPath, util.ApplicationBuildFolder)
-	err = util.ExtractAndRenameDeliverable(dockerBuildPath, deliverable.Path)
+	err = prepareDeliverable(cfg, deliverable, dockerBuildPath, downloadArtifact)
 
 	if err != nil {
-		return "", errors.Wrap(err, "Failed to extract application archive")
+		return "", err
 	}
 
 	// Load metadata
@@ -50,6 +54,29 @@ func Prepare(dockerSpec config.DockerSpec, auroraVersions *runtime.AuroraVersion
 		return "", errors.Wrap(err, "Failed to read application metadata")
 	}
 
+	if meta.Doozer != nil {
+		if err := verifyCopySources(meta.Doozer, applicationFolder); err != nil {
+			return "", err
+		}
+	}
+
+	if meta.Docker != nil {
+		extensions, err := docker.NewDockerExtensions(meta.Docker.Extensions)
+		if err != nil {
+			return "", err
+		}
+		if err := extensions.CopyFiles(applicationFolder, dockerBuildPath); err != nil {
+			return "", err
+		}
+	}
+
+	dockerSpec.Templates, err = templates.Load(dockerSpec.TemplateDir, baseImage.GetTemplateVersion())
+
+	if err != nil {
+		return "", err
+	}
+	dockerSpec.HealthCheckTool = baseImage.GetHealthCheckTool()
+
 	fileWriter := util.NewFileWriter(dockerBuildPath)
 
 	logrus.Info("Running radish build (doozer)")
@@ -58,8 +85,13 @@ func Prepare(dockerSpec config.DockerSpec, auroraVersions *runtime.AuroraVersion
 	}
 
 	destinationPath := baseImage.ImageInfo.Labels["www.skatteetaten.no-destinationPath"]
+	home := baseImage.ImageInfo.Enviroment[docker.ENV_HOME]
+
+	if meta.Docker != nil {
+		meta.Docker.AddBaseImageDefaults(baseImage)
+	}
 
-	if err = fileWriter(NewDockerFile(dockerSpec, *auroraVersions, *meta, baseImage.DockerImage, docker.GetUtcTimestamp(), destinationPath),
+	if err = fileWriter(NewDockerFile(dockerSpec, *auroraVersions, *meta, baseImage.DockerImage, docker.GetUtcTimestamp(), destinationPath, home),
 		"Dockerfile"); err != nil {
 		return "", errors.Wrap(err, "Failed to create Dockerfile")
 	}
@@ -7,6 +7,7 @@ import (
 	"github.com/skatteetaten/architect/pkg/nexus"
 	"github.com/skatteetaten/architect/pkg/util"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -19,7 +20,7 @@ func TestPrepare(t *testing.T) {
 		"0.0.1",
 		"0.0.1-b1.11.0-oracle8-1.0.2")
 
-	dockerBuildPath, err := prepare.Prepare(global.DockerSpec{}, auroraVersions,
+	dockerBuildPath, err := prepare.Prepare(global.Config{}, auroraVersions,
 		nexus.Deliverable{Path: "testdata/test-war-0.0.1-SNAPSHOT-DoozerLeveranse.zip"},
 		runtime.BaseImage{
 			DockerImage: runtime.DockerImage{
@@ -31,7 +32,7 @@ func TestPrepare(t *testing.T) {
 				Enviroment:               make(map[string]string),
 				Labels:                   make(map[string]string),
 			},
-		})
+		}, nil)
 
 	assert.NoError(t, err)
 
@@ -58,3 +59,66 @@ func TestPrepare(t *testing.T) {
 	os.RemoveAll(dockerBuildPath)
 
 }
+
+func TestPrepareSingleFile(t *testing.T) {
+	auroraVersions := runtime.NewAuroraVersion(
+		"0.0.1",
+		true,
+		"0.0.1",
+		"0.0.1-b1.11.0-oracle8-1.0.2")
+	binary, err := ioutil.TempFile("", "doozer-test-app")
+	assert.NoError(t, err)
+	binary.Close()
+	defer os.Remove(binary.Name())
+
+	cfg := global.Config{
+		ApplicationSpec: global.ApplicationSpec{
+			MavenGav: global.MavenGav{
+				ArtifactId: "doozer-test-app",
+				Type:       "bin",
+			},
+			DeliverableMetadata: `{"docker": {"maintainer": "me"}, "doozer": {"srcPath": "doozer-test-app.bin", "destPath": "/u01/bin/"}}`,
+		},
+	}
+	dockerBuildPath, err := prepare.Prepare(cfg, auroraVersions, nexus.Deliverable{Path: binary.Name()},
+		runtime.BaseImage{
+			DockerImage: runtime.DockerImage{
+				Repository: "test",
+				Tag:        "1",
+			},
+			ImageInfo: &runtime.ImageInfo{
+				CompleteBaseImageVersion: "hei",
+				Enviroment:               make(map[string]string),
+				Labels:                   map[string]string{runtime.TemplateVersionLabel: "2"},
+			},
+		}, nil)
+	assert.NoError(t, err)
+	defer os.RemoveAll(dockerBuildPath)
+
+	applicationExists, err := util.Exists(filepath.Join(dockerBuildPath, "app", "application", "doozer-test-app.bin"))
+	assert.NoError(t, err)
+	assert.True(t, applicationExists)
+
+	dockerfile, err := ioutil.ReadFile(filepath.Join(dockerBuildPath, "Dockerfile"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(dockerfile), "COPY ./app/application/doozer-test-app.bin /u01/bin/doozer-test-app.bin")
+}
+
+func TestPrepareSingleFileWithoutMetadata(t *testing.T) {
+	binary, err := ioutil.TempFile("", "doozer-test-app")
+	assert.NoError(t, err)
+	binary.Close()
+	defer os.Remove(binary.Name())
+
+	cfg := global.Config{
+		ApplicationSpec: global.ApplicationSpec{
+			MavenGav: global.MavenGav{
+				ArtifactId: "doozer-test-app",
+				Type:       "bin",
+			},
+		},
+	}
+	_, err = prepare.Prepare(cfg, runtime.NewAuroraVersion("0.0.1", true, "0.0.1", "0.0.1"),
+		nexus.Deliverable{Path: binary.Name()}, runtime.BaseImage{ImageInfo: &runtime.ImageInfo{}}, nil)
+	assert.Error(t, err)
+}
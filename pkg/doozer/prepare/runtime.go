@@ -0,0 +1,117 @@
+package prepare
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/docker"
+	"github.com/skatteetaten/architect/pkg/doozer/config"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var exposedPort = regexp.MustCompile(`^([0-9]+)(/(tcp|udp))?$`)
+var stopSignal = regexp.MustCompile(`^(SIG[A-Z0-9+-]+|[0-9]+)$`)
+
+// createRuntimeInstructions validates and renders how the image runs: WORKDIR, EXPOSE, VOLUME, STOPSIGNAL, USER,
+// ENTRYPOINT and CMD. The image may only run as root when allowRootUser is set
+func createRuntimeInstructions(doozer *config.MetadataDoozer, allowRootUser bool) (string, error) {
+	instructions := make([]string, 0)
+
+	if doozer.Workdir != "" {
+		if err := docker.ValidateAbsolutePath(doozer.Workdir); err != nil {
+			return "", errors.Wrap(err, "Illegal workdir in doozer metadata")
+		}
+		instructions = append(instructions, "WORKDIR "+doozer.Workdir)
+	}
+
+	for _, port := range doozer.ExposedPorts {
+		match := exposedPort.FindStringSubmatch(port)
+		if match == nil {
+			return "", errors.Errorf("Illegal port %s in doozer metadata. Use a port number, optionally with /tcp or /udp", port)
+		}
+		if number, err := strconv.Atoi(match[1]); err != nil || number < 1 || number > 65535 {
+			return "", errors.Errorf("Illegal port %s in doozer metadata. Use a port between 1 and 65535", port)
+		}
+		instructions = append(instructions, "EXPOSE "+port)
+	}
+
+	if len(doozer.Volumes) > 0 {
+		for _, volume := range doozer.Volumes {
+			if err := docker.ValidateAbsolutePath(volume); err != nil {
+				return "", errors.Wrap(err, "Illegal volume in doozer metadata")
+			}
+		}
+		volumes, err := execForm(doozer.Volumes)
+		if err != nil {
+			return "", err
+		}
+		instructions = append(instructions, "VOLUME "+volumes)
+	}
+
+	if doozer.StopSignal != "" {
+		if !stopSignal.MatchString(doozer.StopSignal) {
+			return "", errors.Errorf("Illegal stopSignal %s in doozer metadata. Use a signal name like SIGTERM, or a number", doozer.StopSignal)
+		}
+		instructions = append(instructions, "STOPSIGNAL "+doozer.StopSignal)
+	}
+
+	if doozer.User != "" {
+		if err := docker.ValidateUser(doozer.User, allowRootUser); err != nil {
+			return "", errors.Wrap(err, "Illegal user in doozer metadata")
+		}
+		instructions = append(instructions, "USER "+doozer.User)
+	}
+
+	if len(doozer.Entrypoint) > 0 {
+		entrypoint, err := execForm(doozer.Entrypoint)
+		if err != nil {
+			return "", err
+		}
+		instructions = append(instructions, "ENTRYPOINT "+entrypoint)
+	}
+
+	if len(doozer.Cmd) > 0 {
+		if doozer.CmdScript != "" {
+			return "", errors.New("Doozer metadata cannot contain both cmd and cmdScript")
+		}
+		cmd, err := execForm(doozer.Cmd)
+		if err != nil {
+			return "", err
+		}
+		instructions = append(instructions, "CMD "+cmd)
+	}
+
+	return strings.Join(instructions, "\n"), nil
+}
+
+/*
+rejectRuntimeExtensions keeps the user, ports and volumes of a doozer image in one place. They are set in the doozer
+metadata, where the user is checked against the platform configuration, and not in docker.extensions.
+*/
+func rejectRuntimeExtensions(extensions *docker.DockerExtensions) error {
+	if extensions == nil {
+		return nil
+	}
+	if extensions.User != "" || len(extensions.Ports) > 0 || len(extensions.Volumes) > 0 {
+		return errors.New("Set user, exposedPorts and volumes in the doozer metadata, not in docker.extensions")
+	}
+	return nil
+}
+
+// The exec form of ENTRYPOINT, CMD and VOLUME is a json array
+func execForm(arguments []string) (string, error) {
+	for _, argument := range arguments {
+		if argument == "" {
+			return "", errors.New("Empty argument in doozer metadata")
+		}
+	}
+	buffer := new(bytes.Buffer)
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(arguments); err != nil {
+		return "", errors.Wrap(err, "Failed to render doozer metadata")
+	}
+	return strings.TrimSpace(buffer.String()), nil
+}
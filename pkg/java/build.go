@@ -1,22 +1,56 @@
 package java
 
 import (
+	"bytes"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/skatteetaten/architect/pkg/config"
 	"github.com/skatteetaten/architect/pkg/config/runtime"
 	"github.com/skatteetaten/architect/pkg/docker"
+	deliverable "github.com/skatteetaten/architect/pkg/java/config"
 	"github.com/skatteetaten/architect/pkg/java/prepare"
 	"github.com/skatteetaten/architect/pkg/nexus"
 	"github.com/skatteetaten/architect/pkg/process/build"
+	"github.com/skatteetaten/architect/pkg/util"
+	"strings"
 )
 
-func Prepper() process.Prepper {
+func init() {
+	process.RegisterApplicationType(process.ApplicationTypeSpec{
+		Config: config.ApplicationTypeSpec{
+			Type:           config.JavaLeveransepakke,
+			Names:          []string{"java"},
+			Classifier:     config.Leveransepakke,
+			Packaging:      config.ZipPackaging,
+			Archives:       []config.PackageType{config.ZipPackaging},
+			ParsePackaging: parsePackaging,
+		},
+		Name:              "Java",
+		Prepper:           Prepper,
+		BaseImageResolver: BaseImageResolver(),
+		VersionReader:     VersionReader(),
+		MetadataLoader:    MetadataLoader(),
+		MetadataValidator: MetadataValidator(),
+	})
+}
+
+// PACKAGING is zip for a Leveransepakke, jar for a Spring Boot jar or war
+func parsePackaging(value string) (config.PackageType, error) {
+	packaging := config.PackageType(strings.ToLower(value))
+	if packaging != config.ZipPackaging && packaging != config.JarPackaging && packaging != config.WarPackaging {
+		return "", errors.Errorf("Illegal value %s of PACKAGING. Use zip, jar or war", value)
+	}
+	return packaging, nil
+}
+
+// The downloader is used for the Java agents and the sidecar metadata of the deliverable
+func Prepper(artifactDownloader nexus.Downloader) process.Prepper {
 	return func(cfg *config.Config, auroraVersion *runtime.AuroraVersion, deliverable nexus.Deliverable,
 		baseImage runtime.BaseImage) ([]docker.DockerBuildConfig, error) {
 
 		logrus.Debug("Prepare output image")
-		buildPath, err := prepare.Prepare(cfg.DockerSpec, auroraVersion, deliverable, baseImage)
+		buildPath, err := prepare.Prepare(*cfg, auroraVersion, deliverable, baseImage,
+			nexus.NewArtifactDownloader(artifactDownloader, &cfg.NexusAccess))
 
 		if err != nil {
 			return nil, errors.Wrap(err, "Error prepare artifact")
@@ -31,3 +65,75 @@ func Prepper() process.Prepper {
 		return []docker.DockerBuildConfig{buildConf}, nil
 	}
 }
+
+// BaseImageResolver lets the deliverable choose the base image with docker.baseImage and docker.baseVersion in
+// openshift.json, if the base image policy allows it. For an executable jar or WAR only the metadata in the BuildConfig
+// is used, since the sidecar metadata is downloaded when the image is prepared
+func BaseImageResolver() process.BaseImageResolver {
+	return func(cfg *config.Config, deliverablePackage nexus.Deliverable) (config.DockerBaseImageSpec, string, error) {
+		meta, err := loadMetadata(cfg, deliverablePackage)
+		if err != nil {
+			return config.DockerBaseImageSpec{}, "", errors.Wrap(err, "Failed to read application metadata")
+		} else if meta == nil {
+			return cfg.ApplicationSpec.BaseImageSpec, config.BaseImageFromBuildConfig, nil
+		}
+
+		declared := config.DockerBaseImageSpec{}
+		if meta.Docker != nil {
+			declared.BaseImage = meta.Docker.BaseImage
+			declared.BaseVersion = meta.Docker.BaseVersion
+		}
+		return config.SelectBaseImage(cfg.ApplicationSpec.BaseImageSpec, declared, cfg.BaseImagePolicy)
+	}
+}
+
+// MetadataLoader reads openshift.json in a Leveransepakke, or the metadata in the BuildConfig of an executable jar or WAR
+func MetadataLoader() process.MetadataLoader {
+	return func(cfg *config.Config, deliverablePackage nexus.Deliverable) (interface{}, error) {
+		meta, err := loadMetadata(cfg, deliverablePackage)
+		if err != nil || meta == nil {
+			return nil, err
+		}
+		return meta, nil
+	}
+}
+
+// MetadataValidator checks the metadata with the same rules as when the Dockerfile is written
+func MetadataValidator() process.MetadataValidator {
+	return func(metadata interface{}) error {
+		meta, ok := metadata.(*deliverable.DeliverableMetadata)
+		if !ok {
+			return errors.Errorf("Unexpected java metadata %T", metadata)
+		}
+		return prepare.VerifyMetadata(*meta)
+	}
+}
+
+// loadMetadata returns nil for an executable jar or WAR without metadata in the BuildConfig
+func loadMetadata(cfg *config.Config, deliverablePackage nexus.Deliverable) (*deliverable.DeliverableMetadata, error) {
+	executableArchive, err := prepare.IsExecutableArchive(deliverablePackage.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	if !executableArchive {
+		content, err = util.ReadFileInDeliverable(deliverablePackage.Path, util.DeliveryMetadataPath)
+		if err != nil {
+			return nil, err
+		}
+	} else if cfg.ApplicationSpec.DeliverableMetadata != "" {
+		content = []byte(cfg.ApplicationSpec.DeliverableMetadata)
+	} else {
+		return nil, nil
+	}
+	return deliverable.NewDeliverableMetadata(bytes.NewReader(content))
+}
+
+// VersionReader reads Implementation-Version in an executable jar or WAR, or the version in the root folder of a
+// Leveransepakke
+func VersionReader() process.VersionReader {
+	return func(cfg *config.Config, deliverable nexus.Deliverable) (string, error) {
+		return prepare.DeliverableVersion(deliverable.Path, cfg.ApplicationSpec.MavenGav.ArtifactId)
+	}
+}
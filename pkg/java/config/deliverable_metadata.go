@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/docker"
 	"io"
 	"io/ioutil"
 )
@@ -11,6 +12,8 @@ type DeliverableMetadata struct {
 	Docker    *MetadataDocker    `json:"docker"`
 	Java      *MetadataJava      `json:"java"`
 	Openshift *MetadataOpenShift `json:"openshift"`
+	// The git metadata in the application jar. Not part of openshift.json
+	BuildInfo *docker.BuildInfo `json:"-"`
 }
 
 type MetadataDocker struct {
@@ -18,18 +21,35 @@ type MetadataDocker struct {
 	Labels      map[string]string `json:"labels"`
 	BaseImage   string            `json:"baseImage"`
 	BaseVersion string            `json:"baseVersion"`
+	docker.DeliverableEnv
+	// Optional. Validated by docker.NewDockerExtensions
+	Extensions map[string]json.RawMessage `json:"extensions"`
 }
 
 type MetadataJava struct {
-	MainClass       string `json:"mainClass"`
-	JvmOpts         string `json:"jvmOpts"`
-	ApplicationArgs string `json:"applicationArgs"`
-	ReadinessURL    string `json:"readinessUrl"`
+	MainClass       string              `json:"mainClass"`
+	JvmOpts         string              `json:"jvmOpts"`
+	ApplicationArgs string              `json:"applicationArgs"`
+	ReadinessURL    string              `json:"readinessUrl"`
+	CaCertificates  bool                `json:"caCertificates"` // Optional. Adds the CA certificates embedded in Architect to the truststores
+	Agents          []MetadataJavaAgent `json:"agents"`         // Optional. Java agents downloaded from Nexus
+}
+
+type MetadataJavaAgent struct {
+	Gav     string `json:"gav"`     // groupId:artifactId:version or groupId:artifactId:version:classifier
+	Options string `json:"options"` // Optional. Appended to -javaagent after =
 }
 
 type MetadataOpenShift struct {
-	ReadinessURL              string `json:"readinessUrl"`
-	ReadinessOnManagementPort string `json:"readinessOnManagementPort"`
+	ReadinessURL              string               `json:"readinessUrl"`
+	ReadinessOnManagementPort string               `json:"readinessOnManagementPort"`
+	Healthcheck               *MetadataHealthcheck `json:"healthcheck"` // Optional. Adds a HEALTHCHECK polling the readiness url
+}
+
+type MetadataHealthcheck struct {
+	Interval string `json:"interval"`
+	Timeout  string `json:"timeout"`
+	Retries  int    `json:"retries"`
 }
 
 func NewDeliverableMetadata(reader io.Reader) (*DeliverableMetadata, error) {
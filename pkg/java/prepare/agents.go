@@ -0,0 +1,82 @@
+package prepare
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	global "github.com/skatteetaten/architect/pkg/config"
+	deliverable "github.com/skatteetaten/architect/pkg/java/config"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/skatteetaten/architect/pkg/util"
+	"path/filepath"
+	"strings"
+)
+
+// Where in the application folder the Java agents are put
+const AgentsFolder = "agents"
+
+/*
+prepareJavaAgents downloads the Java agents in the metadata to the agents folder of the application, and adds
+a -javaagent option for each of them to the JVM options in radish.json.
+*/
+func prepareJavaAgents(meta *deliverable.DeliverableMetadata, downloadAgent nexus.ArtifactDownloader, applicationFolder string) error {
+	if meta.Java == nil || len(meta.Java.Agents) == 0 {
+		return nil
+	}
+	if downloadAgent == nil {
+		return errors.New("Deliverable metadata contains \"Java.Agents\", but no agents can be downloaded in this build")
+	}
+
+	javaOptions := make([]string, 0, len(meta.Java.Agents))
+	for _, agent := range meta.Java.Agents {
+		gav, err := parseAgentGav(agent.Gav)
+		if err != nil {
+			return err
+		}
+
+		logrus.Infof("Adding Java agent %s", agent.Gav)
+		downloaded, err := downloadAgent(gav)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to download Java agent %s", agent.Gav)
+		}
+
+		fileName := gav.ArtifactId + "-" + gav.Version + ".jar"
+		if gav.Classifier != "" {
+			fileName = gav.ArtifactId + "-" + gav.Version + "-" + string(gav.Classifier) + ".jar"
+		}
+		if err := util.CopyFile(downloaded.Path, filepath.Join(applicationFolder, AgentsFolder, fileName)); err != nil {
+			return errors.Wrapf(err, "Failed to add Java agent %s", agent.Gav)
+		}
+
+		javaOption := "-javaagent:" + filepath.Join(util.DockerBasedir, util.ApplicationFolder, AgentsFolder, fileName)
+		if agent.Options != "" {
+			javaOption = javaOption + "=" + agent.Options
+		}
+		javaOptions = append(javaOptions, javaOption)
+	}
+
+	meta.Java.JvmOpts = strings.TrimSpace(meta.Java.JvmOpts + " " + strings.Join(javaOptions, " "))
+	return nil
+}
+
+func parseAgentGav(gav string) (*global.MavenGav, error) {
+	parts := strings.Split(gav, ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return nil, errors.Errorf("Illegal Java agent %s. Use groupId:artifactId:version or groupId:artifactId:version:classifier", gav)
+	}
+	for _, part := range parts {
+		if part == "" || strings.ContainsAny(part, "/\\") {
+			return nil, errors.Errorf("Illegal Java agent %s. Use groupId:artifactId:version or groupId:artifactId:version:classifier", gav)
+		}
+	}
+
+	mavenGav := &global.MavenGav{
+		GroupId:    parts[0],
+		ArtifactId: parts[1],
+		Version:    parts[2],
+		Type:       global.JarPackaging,
+	}
+	if len(parts) == 4 {
+		mavenGav.Classifier = global.Classifier(parts[3])
+	}
+	return mavenGav, nil
+}
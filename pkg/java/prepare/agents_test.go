@@ -0,0 +1,59 @@
+package prepare
+
+import (
+	global "github.com/skatteetaten/architect/pkg/config"
+	deliverable "github.com/skatteetaten/architect/pkg/java/config"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/skatteetaten/architect/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrepareJavaAgents(t *testing.T) {
+	applicationFolder, err := ioutil.TempDir("", "agents-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(applicationFolder)
+	downloaded := filepath.Join(applicationFolder, "downloaded.jar")
+	assert.NoError(t, ioutil.WriteFile(downloaded, []byte("agent"), 0644))
+
+	requested := make([]global.MavenGav, 0)
+	downloadAgent := func(gav *global.MavenGav) (nexus.Deliverable, error) {
+		requested = append(requested, *gav)
+		return nexus.Deliverable{Path: downloaded}, nil
+	}
+
+	meta := &deliverable.DeliverableMetadata{Java: &deliverable.MetadataJava{
+		JvmOpts: "-Xmx512m",
+		Agents: []deliverable.MetadataJavaAgent{
+			{Gav: "io.opentelemetry.javaagent:opentelemetry-javaagent:1.2.0:all", Options: "otel.service.name=minarch"},
+			{Gav: "no.skatteetaten.apm:apm-agent:1.0.0"},
+		},
+	}}
+
+	assert.NoError(t, prepareJavaAgents(meta, downloadAgent, applicationFolder))
+	assert.Equal(t, []global.MavenGav{
+		{GroupId: "io.opentelemetry.javaagent", ArtifactId: "opentelemetry-javaagent", Version: "1.2.0", Classifier: "all", Type: global.JarPackaging},
+		{GroupId: "no.skatteetaten.apm", ArtifactId: "apm-agent", Version: "1.0.0", Type: global.JarPackaging},
+	}, requested)
+	assert.Equal(t, "-Xmx512m -javaagent:/u01/application/agents/opentelemetry-javaagent-1.2.0-all.jar=otel.service.name=minarch "+
+		"-javaagent:/u01/application/agents/apm-agent-1.0.0.jar", meta.Java.JvmOpts)
+
+	exists, err := util.Exists(filepath.Join(applicationFolder, "agents", "apm-agent-1.0.0.jar"))
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestPrepareJavaAgentsFailsOnIllegalGav(t *testing.T) {
+	meta := &deliverable.DeliverableMetadata{Java: &deliverable.MetadataJava{
+		Agents: []deliverable.MetadataJavaAgent{{Gav: "io.opentelemetry:../../evil:1.0.0"}},
+	}}
+	downloadAgent := func(gav *global.MavenGav) (nexus.Deliverable, error) {
+		return nexus.Deliverable{}, nil
+	}
+
+	err := prepareJavaAgents(meta, downloadAgent, "")
+	assert.EqualError(t, err, "Illegal Java agent io.opentelemetry:../../evil:1.0.0. Use groupId:artifactId:version or groupId:artifactId:version:classifier")
+}
@@ -0,0 +1,44 @@
+package prepare
+
+import (
+	global "github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/skatteetaten/architect/pkg/java/config"
+	"github.com/skatteetaten/architect/pkg/util"
+)
+
+// The descriptor the base image uses to start the application, and the Dockerfile, for one image architecture label
+type imageArchitecture struct {
+	Name           string
+	DescriptorFile string
+	Descriptor     func(meta *config.DeliverableMetadata, basedir string) util.WriterFunc
+	Dockerfile     func(dockerSpec global.DockerSpec, auroraVersion runtime.AuroraVersion, meta config.DeliverableMetadata,
+		baseImage runtime.DockerImage, imageBuildTime string) util.WriterFunc
+}
+
+var imageArchitectures = map[string]imageArchitecture{
+	"java": {
+		Name:           "radish",
+		DescriptorFile: "radish.json",
+		Descriptor:     newRadishDescriptor,
+		Dockerfile:     NewRadishDockerFile,
+	},
+	"java-test": {
+		Name:           "test image",
+		DescriptorFile: "radish.json",
+		Descriptor:     newRadishDescriptor,
+		Dockerfile:     NewRadishTestImageDockerFile,
+	},
+}
+
+func findImageArchitecture(baseImage runtime.BaseImage) (imageArchitecture, error) {
+	architecture := baseImage.GetImageArchitecture()
+	if handler, exists := imageArchitectures[architecture]; exists {
+		return handler, nil
+	}
+	supported := make([]string, 0, len(imageArchitectures))
+	for label := range imageArchitectures {
+		supported = append(supported, label)
+	}
+	return imageArchitecture{}, runtime.UnsupportedImageArchitectureError(architecture, supported)
+}
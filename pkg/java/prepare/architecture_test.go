@@ -0,0 +1,28 @@
+package prepare
+
+import (
+	"testing"
+
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindImageArchitecture(t *testing.T) {
+	architecture, err := findImageArchitecture(baseImageWithLabels(map[string]string{runtime.ImageArchitectureLabel: "java"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "radish", architecture.Name)
+	assert.Equal(t, "radish.json", architecture.DescriptorFile)
+
+	_, err = findImageArchitecture(baseImageWithLabels(map[string]string{runtime.ImageArchitectureLabel: "cobol"}))
+	assert.EqualError(t, err, `The base image provided has an unsupported www.skatteetaten.no-imageArchitecture label "cobol". Supported values are [java, java-test]`)
+
+	_, err = findImageArchitecture(baseImageWithLabels(map[string]string{}))
+	assert.EqualError(t, err, "The base image provided has no www.skatteetaten.no-imageArchitecture label. Supported values are [java, java-test]. Make sure you use the latest version")
+}
+
+func baseImageWithLabels(labels map[string]string) runtime.BaseImage {
+	return runtime.BaseImage{
+		DockerImage: runtime.DockerImage{Repository: "test", Tag: "1"},
+		ImageInfo:   &runtime.ImageInfo{Labels: labels},
+	}
+}
@@ -0,0 +1,70 @@
+package prepare
+
+import (
+	"archive/zip"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/skatteetaten/architect/pkg/docker"
+	"github.com/skatteetaten/architect/pkg/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Where git-commit-id-plugin and the Gradle git properties plugin put the git metadata
+var gitPropertiesPaths = []string{"META-INF/git.properties", "git.properties"}
+
+/*
+findBuildInfo reads the git metadata in the application jar in lib/. It returns nil when the application has no
+git.properties, since the metadata is optional.
+*/
+func findBuildInfo(applicationFolder string, applicationJarPrefix string) (*docker.BuildInfo, error) {
+	if applicationJarPrefix == "" {
+		return nil, nil
+	}
+	libraries, err := ioutil.ReadDir(filepath.Join(applicationFolder, "lib"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, library := range libraries {
+		if library.IsDir() || !strings.HasPrefix(library.Name(), applicationJarPrefix) || !strings.HasSuffix(library.Name(), ".jar") {
+			continue
+		}
+		jarPath := filepath.Join(applicationFolder, "lib", library.Name())
+		properties, err := readGitProperties(jarPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to read git.properties in %s", library.Name())
+		}
+		if properties != nil {
+			logrus.Infof("Found git metadata in %s", library.Name())
+			return docker.NewBuildInfoFromGitProperties(properties), nil
+		}
+	}
+	return nil, nil
+}
+
+func readGitProperties(jarPath string) (map[string]string, error) {
+	zipReader, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zipReader.Close()
+
+	for _, path := range gitPropertiesPaths {
+		for _, zipEntry := range zipReader.File {
+			if zipEntry.Name != path {
+				continue
+			}
+			reader, err := zipEntry.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer reader.Close()
+			return util.ReadProperties(reader)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,246 @@
+package prepare
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	global "github.com/skatteetaten/architect/pkg/config"
+	deliverable "github.com/skatteetaten/architect/pkg/java/config"
+	"github.com/skatteetaten/architect/pkg/java/prepare/resources"
+	"github.com/skatteetaten/architect/pkg/util"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Where in the build folder and in $HOME the truststores are put
+	SecurityFolder = "security"
+	TrustStoreFile = "cacerts"
+	CaBundleFile   = "ca-bundle.pem"
+	// The script adding the mounted certificates to the truststores of the base image
+	MergeCaCertificatesScript = "merge-ca-certificates.sh"
+	mountedFolder             = "mounted"
+
+	trustStorePassword = "changeit"
+)
+
+/*
+Runs in the image build, since the truststore of the JDK and the CA bundle of the base image can only be read there.
+The mounted certificates are added to copies of them in $HOME/security.
+*/
+const mergeCaCertificatesScript = `#!/bin/sh
+set -e
+security="$HOME/security"
+for cacerts in "$JAVA_HOME/lib/security/cacerts" "$JAVA_HOME/jre/lib/security/cacerts"; do
+  if [ -f "$cacerts" ]; then
+    cp "$cacerts" "$security/cacerts"
+    break
+  fi
+done
+if [ ! -f "$security/cacerts" ]; then
+  echo "Found no truststore in the JDK of the base image. Is JAVA_HOME set?" >&2
+  exit 1
+fi
+for bundle in /etc/pki/tls/certs/ca-bundle.crt /etc/ssl/certs/ca-certificates.crt; do
+  if [ -f "$bundle" ]; then
+    cp "$bundle" "$security/ca-bundle.pem"
+    break
+  fi
+done
+if [ ! -f "$security/ca-bundle.pem" ]; then
+  echo "Found no CA bundle in the base image. The bundle only contains the mounted CA certificates" >&2
+fi
+for certificate in "$security"/mounted/*.pem; do
+  "$JAVA_HOME/bin/keytool" -importcert -noprompt -keystore "$security/cacerts" -storepass changeit \
+    -alias "mounted-$(basename "$certificate" .pem)" -file "$certificate"
+  cat "$certificate" >> "$security/ca-bundle.pem"
+done
+chmod 644 "$security/cacerts" "$security/ca-bundle.pem"
+`
+
+var illegalAliasCharacters = regexp.MustCompile("[^a-z0-9._-]+")
+
+// The BuildConfig decides where the certificates come from. The flag in the metadata only adds the embedded bundle
+func findCaCertificates(spec global.CaCertificatesSpec, meta *deliverable.DeliverableMetadata) global.CaCertificatesSpec {
+	if !spec.Enabled() && meta.Java != nil && meta.Java.CaCertificates {
+		spec.Embedded = true
+	}
+	return spec
+}
+
+/*
+prepareCaCertificates writes a JVM truststore and a PEM bundle with the CA certificates to the security folder.
+
+Without the embedded bundle the mounted certificates are written one per file, with the script that adds them to the
+truststore of the JDK and the CA bundle of the base image. The public CAs of the base image are kept.
+*/
+func prepareCaCertificates(spec global.CaCertificatesSpec, fileWriter util.FileWriter) error {
+	certificates := make([]trustedCertificate, 0)
+
+	if spec.Embedded {
+		cacerts, err := resources.Asset(TrustStoreFile)
+		if err != nil {
+			return errors.Wrap(err, "Failed to load the embedded CA certificates")
+		}
+		embedded, err := readKeyStore(bytes.NewReader(cacerts), trustStorePassword)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the embedded CA certificates")
+		}
+		certificates = append(certificates, embedded...)
+	}
+
+	if spec.Directory != "" {
+		mounted, err := loadPemCertificates(spec.Directory)
+		if err != nil {
+			return err
+		}
+		certificates = mergeCertificates(certificates, mounted)
+	}
+
+	if mergeCaCertificatesInImage(spec) {
+		return writeMountedCertificates(certificates, fileWriter)
+	}
+
+	if len(certificates) == 0 {
+		return errors.New("Found no CA certificates to add to the image")
+	}
+	logrus.Infof("Adding %d CA certificates to the image", len(certificates))
+
+	if err := fileWriter(func(writer io.Writer) error {
+		return writeKeyStore(writer, trustStorePassword, certificates)
+	}, SecurityFolder, TrustStoreFile); err != nil {
+		return errors.Wrap(err, "Failed to create truststore")
+	}
+	if err := fileWriter(func(writer io.Writer) error {
+		for _, certificate := range certificates {
+			if err := pem.Encode(writer, &pem.Block{Type: "CERTIFICATE", Bytes: certificate.Certificate.Raw}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, SecurityFolder, CaBundleFile); err != nil {
+		return errors.Wrap(err, "Failed to create CA bundle")
+	}
+	return nil
+}
+
+// The certificates are added to the truststores of the base image when the embedded bundle is not used
+func mergeCaCertificatesInImage(spec global.CaCertificatesSpec) bool {
+	return !spec.Embedded && spec.Directory != ""
+}
+
+func writeMountedCertificates(certificates []trustedCertificate, fileWriter util.FileWriter) error {
+	if len(certificates) == 0 {
+		return errors.New("Found no CA certificates to add to the image")
+	}
+	logrus.Infof("Adding %d CA certificates to the truststores of the base image", len(certificates))
+
+	for _, certificate := range certificates {
+		block := &pem.Block{Type: "CERTIFICATE", Bytes: certificate.Certificate.Raw}
+		if err := fileWriter(func(writer io.Writer) error {
+			return pem.Encode(writer, block)
+		}, SecurityFolder, mountedFolder, certificate.Alias+".pem"); err != nil {
+			return errors.Wrapf(err, "Failed to write CA certificate %s", certificate.Alias)
+		}
+	}
+	if err := fileWriter(func(writer io.Writer) error {
+		_, err := io.WriteString(writer, mergeCaCertificatesScript)
+		return err
+	}, SecurityFolder, MergeCaCertificatesScript); err != nil {
+		return errors.Wrap(err, "Failed to write the script merging the CA certificates")
+	}
+	return nil
+}
+
+// The alias of a mounted certificate is the file name, with a number added for files with more than one certificate
+func loadPemCertificates(directory string) ([]trustedCertificate, error) {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read CA certificates in %s", directory)
+	}
+
+	certificates := make([]trustedCertificate, 0)
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(directory, file.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to read CA certificate %s", file.Name())
+		}
+
+		alias := illegalAliasCharacters.ReplaceAllString(strings.ToLower(strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))), "-")
+		found := 0
+		for block, rest := pem.Decode(content); block != nil; block, rest = pem.Decode(rest) {
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to parse CA certificate in %s", file.Name())
+			}
+			found++
+			certificateAlias := alias
+			if found > 1 {
+				certificateAlias = alias + "-" + strconv.Itoa(found)
+			}
+			certificates = append(certificates, trustedCertificate{
+				Alias:       certificateAlias,
+				Created:     file.ModTime(),
+				Certificate: certificate,
+			})
+		}
+		if found == 0 {
+			return nil, errors.Errorf("Found no PEM certificates in %s", file.Name())
+		}
+	}
+	return certificates, nil
+}
+
+// Certificates already in the truststore are skipped, and aliases are made unique
+func mergeCertificates(certificates []trustedCertificate, added []trustedCertificate) []trustedCertificate {
+	aliases := make(map[string]bool)
+	for _, certificate := range certificates {
+		aliases[certificate.Alias] = true
+	}
+
+	for _, certificate := range added {
+		if containsCertificate(certificates, certificate.Certificate) {
+			logrus.Debugf("CA certificate %s is already in the truststore", certificate.Alias)
+			continue
+		}
+		alias := certificate.Alias
+		for i := 2; aliases[alias]; i++ {
+			alias = certificate.Alias + "-" + strconv.Itoa(i)
+		}
+		aliases[alias] = true
+		if certificate.Created.IsZero() {
+			certificate.Created = time.Now()
+		}
+		certificate.Alias = alias
+		certificates = append(certificates, certificate)
+	}
+	return certificates
+}
+
+func containsCertificate(certificates []trustedCertificate, certificate *x509.Certificate) bool {
+	for _, existing := range certificates {
+		if existing.Certificate.Equal(certificate) {
+			return true
+		}
+	}
+	return false
+}
+
+// The JVM options telling Java to use the truststore in the image
+func trustStoreJavaOptions() string {
+	trustStore := filepath.Join(util.DockerBasedir, SecurityFolder, TrustStoreFile)
+	return "-Djavax.net.ssl.trustStore=" + trustStore + " -Djavax.net.ssl.trustStorePassword=" + trustStorePassword
+}
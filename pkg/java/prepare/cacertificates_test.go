@@ -0,0 +1,102 @@
+package prepare
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	global "github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPrepareCaCertificates(t *testing.T) {
+	mounted, err := ioutil.TempDir("", "ca-certificates-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(mounted)
+	buildFolder, err := ioutil.TempDir("", "ca-certificates-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(buildFolder)
+
+	corporateRoot := writeTestCertificate(t, filepath.Join(mounted, "Corporate Root.pem"), "Corporate Root CA")
+
+	spec := global.CaCertificatesSpec{Embedded: true, Directory: mounted}
+	assert.NoError(t, prepareCaCertificates(spec, util.NewFileWriter(buildFolder)))
+
+	trustStore, err := os.Open(filepath.Join(buildFolder, "security", "cacerts"))
+	assert.NoError(t, err)
+	defer trustStore.Close()
+	certificates, err := readKeyStore(trustStore, "changeit")
+	assert.NoError(t, err)
+	assert.Len(t, certificates, 119)
+	assert.Equal(t, "corporate-root", certificates[118].Alias)
+	assert.True(t, certificates[118].Certificate.Equal(corporateRoot))
+
+	bundle, err := ioutil.ReadFile(filepath.Join(buildFolder, "security", "ca-bundle.pem"))
+	assert.NoError(t, err)
+	assert.Equal(t, 119, bytes.Count(bundle, []byte("BEGIN CERTIFICATE")))
+}
+
+func TestPrepareMountedCaCertificatesKeepsTheBaseImageTruststores(t *testing.T) {
+	mounted, err := ioutil.TempDir("", "ca-certificates-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(mounted)
+	buildFolder, err := ioutil.TempDir("", "ca-certificates-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(buildFolder)
+
+	corporateRoot := writeTestCertificate(t, filepath.Join(mounted, "Corporate Root.pem"), "Corporate Root CA")
+
+	spec := global.CaCertificatesSpec{Directory: mounted}
+	assert.NoError(t, prepareCaCertificates(spec, util.NewFileWriter(buildFolder)))
+
+	_, err = os.Stat(filepath.Join(buildFolder, "security", "cacerts"))
+	assert.True(t, os.IsNotExist(err))
+	content, err := ioutil.ReadFile(filepath.Join(buildFolder, "security", "mounted", "corporate-root.pem"))
+	assert.NoError(t, err)
+	block, _ := pem.Decode(content)
+	assert.Equal(t, corporateRoot.Raw, block.Bytes)
+
+	script, err := ioutil.ReadFile(filepath.Join(buildFolder, "security", "merge-ca-certificates.sh"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(script), `cp "$cacerts" "$security/cacerts"`)
+}
+
+func TestLoadPemCertificatesFailsWithoutCertificates(t *testing.T) {
+	mounted, err := ioutil.TempDir("", "ca-certificates-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(mounted)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(mounted, "README"), []byte("not a certificate"), 0644))
+
+	_, err = loadPemCertificates(mounted)
+	assert.EqualError(t, err, "Found no PEM certificates in README")
+}
+
+func writeTestCertificate(t *testing.T, path string, commonName string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return certificate
+}
@@ -0,0 +1,169 @@
+package prepare
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/skatteetaten/architect/pkg/java/config"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Label or environment variable on the base image telling which JDK it contains, e.g. 8, 1.8 or 17.0.2
+	JavaVersionLabel = "www.skatteetaten.no-javaVersion"
+	JavaVersionEnv   = "JAVA_VERSION_MAJOR"
+
+	// Class file major version 45 is Java 1.1, 52 is Java 8
+	classFileVersionOffset = 44
+	classFileMagic         = 0xCAFEBABE
+)
+
+type classScanResult struct {
+	// The highest class file major version found, and the class that has it
+	MaxMajorVersion int
+	MaxVersionClass string
+	MainClassFound  bool
+}
+
+/*
+verifyClassVersions scans the jars in the class library folders of the extracted application. It fails if the
+classes are compiled for a newer Java than the JDK in the base image.
+
+A main class that is not in any of the jars only gives a warning, since it may come from the base image or a
+classpath folder. The check of the class versions is skipped if the base image does not tell which JDK it contains.
+*/
+func verifyClassVersions(applicationFolder string, meta *config.DeliverableMetadata, baseImage runtime.BaseImage) error {
+	mainClass := ""
+	if meta.Java != nil {
+		mainClass = meta.Java.MainClass
+	}
+
+	result, err := scanClassLibraries(applicationFolder, mainClass)
+	if err != nil {
+		return errors.Wrap(err, "Failed to scan the class libraries of the application")
+	}
+
+	if mainClass != "" && !result.MainClassFound {
+		logrus.Warnf("Main class %s from \"Java.MainClass\" is not found in any jar in %s. The application may not start",
+			mainClass, strings.Join(classLibraryFolders, " or "))
+	}
+
+	javaVersion, exists := findJavaVersion(baseImage)
+	if !exists {
+		logrus.Debugf("The base image has no %s label or %s env. Skipping the class version check", JavaVersionLabel, JavaVersionEnv)
+		return nil
+	}
+	if result.MaxMajorVersion > javaVersion+classFileVersionOffset {
+		return errors.Errorf("The class %s is compiled for Java %d, but the base image %s has Java %d",
+			result.MaxVersionClass, result.MaxMajorVersion-classFileVersionOffset, baseImage.GetCompleteDockerTagName(), javaVersion)
+	}
+	return nil
+}
+
+func scanClassLibraries(applicationFolder string, mainClass string) (*classScanResult, error) {
+	result := &classScanResult{}
+	mainClassFile := strings.Replace(mainClass, ".", "/", -1) + ".class"
+
+	for _, folder := range classLibraryFolders {
+		libraryFolder := filepath.Join(applicationFolder, folder)
+		if _, err := os.Stat(libraryFolder); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.Walk(libraryFolder, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".jar") {
+				return nil
+			}
+			return scanJar(path, mainClassFile, result)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func scanJar(jarPath string, mainClassFile string, result *classScanResult) error {
+	jar, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to open %s", filepath.Base(jarPath))
+	}
+	defer jar.Close()
+
+	for _, entry := range jar.File {
+		if !strings.HasSuffix(entry.Name, ".class") || skipClassVersionCheck(entry.Name) {
+			continue
+		}
+		if entry.Name == mainClassFile {
+			result.MainClassFound = true
+		}
+		majorVersion, err := readClassMajorVersion(entry)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to read %s in %s", entry.Name, filepath.Base(jarPath))
+		}
+		if majorVersion > result.MaxMajorVersion {
+			result.MaxMajorVersion = majorVersion
+			result.MaxVersionClass = entry.Name
+		}
+	}
+	return nil
+}
+
+// Multi-release jars and module descriptors are compiled for a newer Java on purpose, and are ignored by older JVMs
+func skipClassVersionCheck(name string) bool {
+	return strings.HasPrefix(name, "META-INF/versions/") || filepath.Base(name) == "module-info.class"
+}
+
+func readClassMajorVersion(entry *zip.File) (int, error) {
+	reader, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, err
+	}
+	if binary.BigEndian.Uint32(header[0:4]) != classFileMagic {
+		return 0, errors.New("Not a class file")
+	}
+	return int(binary.BigEndian.Uint16(header[6:8])), nil
+}
+
+func findJavaVersion(baseImage runtime.BaseImage) (int, bool) {
+	if baseImage.ImageInfo == nil {
+		return 0, false
+	}
+	value, exists := baseImage.ImageInfo.Labels[JavaVersionLabel]
+	if !exists {
+		value, exists = baseImage.ImageInfo.Enviroment[JavaVersionEnv]
+	}
+	if !exists {
+		return 0, false
+	}
+	version, err := parseJavaVersion(value)
+	if err != nil {
+		logrus.Warnf("Unable to parse the Java version %s of the base image: %s", value, err)
+		return 0, false
+	}
+	return version, true
+}
+
+// Handles both the old 1.8.0_252 and the new 17.0.2 version scheme
+func parseJavaVersion(version string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	if len(parts) > 1 && parts[0] == "1" {
+		parts = parts[1:]
+	}
+	major := strings.SplitN(parts[0], "_", 2)[0]
+	return strconv.Atoi(major)
+}
@@ -0,0 +1,73 @@
+package prepare
+
+import (
+	"archive/zip"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/skatteetaten/architect/pkg/java/config"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseJavaVersion(t *testing.T) {
+	for value, expected := range map[string]int{"8": 8, "1.8": 8, "1.8.0_252": 8, "11": 11, "17.0.2": 17, " 21 ": 21} {
+		version, err := parseJavaVersion(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, version, value)
+	}
+	_, err := parseJavaVersion("latest")
+	assert.Error(t, err)
+}
+
+func TestVerifyClassVersions(t *testing.T) {
+	applicationFolder, err := ioutil.TempDir("", "classversion-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(applicationFolder)
+
+	writeTestJar(t, filepath.Join(applicationFolder, "lib", "app.jar"), map[string]uint16{
+		"no/skatteetaten/Main.class":          52,
+		"META-INF/versions/17/Foo.class":      61,
+		"module-info.class":                   53,
+		"no/skatteetaten/internal/Util.class": 52,
+	})
+	writeTestJar(t, filepath.Join(applicationFolder, "repo", "no", "skatteetaten", "lib.jar"), map[string]uint16{
+		"no/skatteetaten/lib/Lib.class": 55,
+	})
+
+	meta := &config.DeliverableMetadata{Java: &config.MetadataJava{MainClass: "no.skatteetaten.Main"}}
+
+	assert.NoError(t, verifyClassVersions(applicationFolder, meta, baseImageWithJava(map[string]string{JavaVersionLabel: "11"}, nil)))
+	assert.NoError(t, verifyClassVersions(applicationFolder, meta, baseImageWithJava(nil, nil)))
+
+	err = verifyClassVersions(applicationFolder, meta, baseImageWithJava(nil, map[string]string{JavaVersionEnv: "8"}))
+	assert.EqualError(t, err, "The class no/skatteetaten/lib/Lib.class is compiled for Java 11, but the base image test:1 has Java 8")
+
+	// The main class may come from the base image
+	meta.Java.MainClass = "no.skatteetaten.Missing"
+	assert.NoError(t, verifyClassVersions(applicationFolder, meta, baseImageWithJava(nil, nil)))
+}
+
+func baseImageWithJava(labels map[string]string, env map[string]string) runtime.BaseImage {
+	return runtime.BaseImage{
+		DockerImage: runtime.DockerImage{Repository: "test", Tag: "1"},
+		ImageInfo:   &runtime.ImageInfo{Labels: labels, Enviroment: env},
+	}
+}
+
+func writeTestJar(t *testing.T, path string, classes map[string]uint16) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	jar := zip.NewWriter(f)
+	for name, majorVersion := range classes {
+		w, err := jar.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte{0xCA, 0xFE, 0xBA, 0xBE, 0, 0, byte(majorVersion >> 8), byte(majorVersion)})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, jar.Close())
+}
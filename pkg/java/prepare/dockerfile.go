@@ -6,75 +6,100 @@ import (
 	"github.com/skatteetaten/architect/pkg/config/runtime"
 	"github.com/skatteetaten/architect/pkg/docker"
 	"github.com/skatteetaten/architect/pkg/java/config"
+	"github.com/skatteetaten/architect/pkg/templates"
 	"github.com/skatteetaten/architect/pkg/util"
 	"io"
+	"path/filepath"
 )
 
-var radishDockerFileTemplate string = `FROM {{.BaseImage}}
-
-MAINTAINER {{.Maintainer}}
-LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
-
-COPY ./app radish.json $HOME/
-RUN find $HOME/application -type d -exec chmod 755 {} + && \
-	find $HOME/application -type f -exec chmod 644 {} + && \
-	mkdir -p $HOME/logs && \
-	chmod 777 $HOME/logs && \
-	ln -s $HOME/logs $HOME/application/logs
-
-ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
-`
-
-//TODO: Hack. Remove code later
-var radishTestImageDockerFile string = `FROM {{.BaseImage}}
-
-MAINTAINER {{.Maintainer}}
-LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
-
-COPY ./app radish.json $HOME/
-RUN find $HOME/application -type d -exec chmod 777 {} + && \
-	find $HOME/application -type f -exec chmod 644 {} + && \
-	mkdir -p $HOME/logs && \
-	chmod 777 $HOME/logs && \
-	ln -s $HOME/logs $HOME/application/logs
-
-ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
-`
-
 type DockerfileData struct {
-	BaseImage  string
-	Maintainer string
-	Labels     map[string]string
-	Env        map[string]string
+	BaseImage   string
+	Maintainer  string
+	Layers      []string
+	Labels      map[string]string
+	Env         map[string]string
+	HealthCheck *docker.HealthCheck
+	// The truststores with CA certificates are copied to $HOME/security
+	CaCertificates bool
+	// The mounted CA certificates are added to the truststores of the base image
+	MergeCaCertificates bool
+	// The instructions from docker.extensions in the metadata
+	Extensions string
 }
 
-func createEnv(auroraVersion runtime.AuroraVersion, pushextratags global.PushExtraTags, imageBuildTime string) map[string]string {
-	env := make(map[string]string)
+func createEnv(auroraVersion runtime.AuroraVersion, dockerSpec global.DockerSpec, imageBuildTime string, meta config.DeliverableMetadata) (map[string]string, error) {
+	env, _, err := docker.ReadinessEnv(findReadiness(meta))
+	if err != nil {
+		return nil, err
+	}
 	env[docker.ENV_APP_VERSION] = string(auroraVersion.GetAppVersion())
 	env[docker.ENV_AURORA_VERSION] = auroraVersion.GetCompleteVersion()
-	env[docker.ENV_PUSH_EXTRA_TAGS] = pushextratags.ToStringValue()
-	env[docker.TZ] = "Europe/Oslo"
+	env[docker.ENV_PUSH_EXTRA_TAGS] = dockerSpec.PushExtraTags.ToStringValue()
 	env[docker.IMAGE_BUILD_TIME] = imageBuildTime
 
 	if auroraVersion.Snapshot {
 		env[docker.ENV_SNAPSHOT_TAG] = auroraVersion.GetGivenVersion()
 	}
 
-	return env
+	if dockerSpec.CaCertificates.Enabled() {
+		env[docker.ENV_SSL_CERT_FILE] = filepath.Join(util.DockerBasedir, SecurityFolder, CaBundleFile)
+	}
+
+	deliverableEnv := meta.Docker.DeliverableEnv
+	if deliverableEnv.Timezone == "" {
+		deliverableEnv.Timezone = docker.DefaultTimezone
+	}
+	if err := deliverableEnv.AddTo(env); err != nil {
+		return nil, err
+	}
+
+	return env, nil
 }
 
-func createLabels(meta config.DeliverableMetadata) map[string]string {
-	var labels = make(map[string]string)
+func createLabels(auroraVersion runtime.AuroraVersion, imageBuildTime string, meta config.DeliverableMetadata) map[string]string {
+	// The labels in the metadata have precedence over the OCI labels
+	var labels = docker.OciLabels(meta.BuildInfo, string(auroraVersion.GetAppVersion()), imageBuildTime)
 
 	for k, v := range meta.Docker.Labels {
 		labels[k] = v
 	}
 
+	// The readiness url is validated when the env is created
+	_, readinessLabels, _ := docker.ReadinessEnv(findReadiness(meta))
+	for k, v := range readinessLabels {
+		labels[k] = v
+	}
+
 	return labels
 }
 
-// TODO Consider moving this func
-func verifyMetadata(meta config.DeliverableMetadata) error {
+// The readiness url in the openshift element has precedence over the one in the java element
+func findReadiness(meta config.DeliverableMetadata) (string, string) {
+	var readinessURL, onManagementPort string
+	if meta.Openshift != nil {
+		readinessURL = meta.Openshift.ReadinessURL
+		onManagementPort = meta.Openshift.ReadinessOnManagementPort
+	}
+	if readinessURL == "" && meta.Java != nil {
+		readinessURL = meta.Java.ReadinessURL
+	}
+	return readinessURL, onManagementPort
+}
+
+func createHealthCheck(meta config.DeliverableMetadata, tool string) (*docker.HealthCheck, error) {
+	if meta.Openshift == nil || meta.Openshift.Healthcheck == nil {
+		return nil, nil
+	}
+	readinessURL, onManagementPort := findReadiness(meta)
+	if readinessURL == "" {
+		return nil, errors.New("Deliverable metadata contains \"Openshift.Healthcheck\" but no readiness url")
+	}
+	healthcheck := meta.Openshift.Healthcheck
+	return docker.NewHealthCheck(readinessURL, onManagementPort, healthcheck.Interval, healthcheck.Timeout, healthcheck.Retries, tool)
+}
+
+// VerifyMetadata is also the metadata validator of the java application type
+func VerifyMetadata(meta config.DeliverableMetadata) error {
 	if meta.Docker == nil {
 		return errors.Errorf("Deliverable metadata does not contain \"Docker\" element")
 	} else if meta.Docker.Maintainer == "" {
@@ -84,41 +109,75 @@ func verifyMetadata(meta config.DeliverableMetadata) error {
 	return nil
 }
 
+// The legacy templates copy the extracted application, and have none of the instructions added later
+func verifyTemplateVersion(templateSet *templates.TemplateSet, data *DockerfileData) error {
+	if !templateSet.Legacy() {
+		return nil
+	}
+	if data.CaCertificates {
+		return templates.LegacyError("CA_CERTIFICATES")
+	}
+	if data.HealthCheck != nil {
+		return templates.LegacyError("Openshift.Healthcheck in the deliverable metadata")
+	}
+	if data.Extensions != "" {
+		return templates.LegacyError("Docker.Extensions in the deliverable metadata")
+	}
+	return nil
+}
+
 func NewRadishDockerFile(dockerSpec global.DockerSpec, auroraVersion runtime.AuroraVersion, meta config.DeliverableMetadata,
 	baseImage runtime.DockerImage, imageBuildTime string) util.WriterFunc {
-	return func(writer io.Writer) error {
-
-		if err := verifyMetadata(meta); err != nil {
-			return err
-		}
-		env := createEnv(auroraVersion, dockerSpec.PushExtraTags, imageBuildTime)
-		data := &DockerfileData{
-			BaseImage:  baseImage.GetCompleteDockerTagName(),
-			Maintainer: meta.Docker.Maintainer,
-			Labels:     createLabels(meta),
-			Env:        env,
-		}
-
-		return util.NewTemplateWriter(data, "Dockerfile", radishDockerFileTemplate)(writer)
-	}
+	return newDockerFile(templates.JavaDockerfile, dockerSpec, auroraVersion, meta, baseImage, imageBuildTime)
 }
 
 //TODO: Hack. Remove code later
+// The test image has its own template, since it makes the folders of the application world writable
 func NewRadishTestImageDockerFile(dockerSpec global.DockerSpec, auroraVersion runtime.AuroraVersion, meta config.DeliverableMetadata,
+	baseImage runtime.DockerImage, imageBuildTime string) util.WriterFunc {
+	return newDockerFile(templates.JavaTestImageDockerfile, dockerSpec, auroraVersion, meta, baseImage, imageBuildTime)
+}
+
+func newDockerFile(templateName string, dockerSpec global.DockerSpec, auroraVersion runtime.AuroraVersion, meta config.DeliverableMetadata,
 	baseImage runtime.DockerImage, imageBuildTime string) util.WriterFunc {
 	return func(writer io.Writer) error {
 
-		if err := verifyMetadata(meta); err != nil {
+		if err := VerifyMetadata(meta); err != nil {
+			return err
+		}
+		env, err := createEnv(auroraVersion, dockerSpec, imageBuildTime, meta)
+		if err != nil {
+			return err
+		}
+		healthCheck, err := createHealthCheck(meta, dockerSpec.HealthCheckTool)
+		if err != nil {
 			return err
 		}
-		env := createEnv(auroraVersion, dockerSpec.PushExtraTags, imageBuildTime)
+		extensions, err := docker.NewDockerExtensions(meta.Docker.Extensions)
+		if err != nil {
+			return err
+		}
+		labels := createLabels(auroraVersion, imageBuildTime, meta)
+		extensions.AddLabels(labels)
 		data := &DockerfileData{
-			BaseImage:  baseImage.GetCompleteDockerTagName(),
-			Maintainer: meta.Docker.Maintainer,
-			Labels:     createLabels(meta),
-			Env:        env,
+			BaseImage:           baseImage.GetCompleteDockerTagName(),
+			Maintainer:          meta.Docker.Maintainer,
+			Layers:              ImageLayers,
+			Labels:              labels,
+			Env:                 env,
+			HealthCheck:         healthCheck,
+			Extensions:          extensions.Instructions(),
+			CaCertificates:      dockerSpec.CaCertificates.Enabled(),
+			MergeCaCertificates: mergeCaCertificatesInImage(dockerSpec.CaCertificates),
 		}
 
-		return util.NewTemplateWriter(data, "Dockerfile", radishTestImageDockerFile)(writer)
+		if err := verifyTemplateVersion(dockerSpec.Templates, data); err != nil {
+			return err
+		}
+		dockerfileTemplate, err := dockerSpec.Templates.Get(templateName)
+		if err != nil {
+			return err
+		}
+		return util.NewTemplateWriter(data, "Dockerfile", dockerfileTemplate)(writer)
 	}
 }
@@ -0,0 +1,228 @@
+package prepare
+
+import (
+	"archive/zip"
+	"bufio"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	global "github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/skatteetaten/architect/pkg/util"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+/*
+Besides the Leveransepakke zip, a Java deliverable can be an executable jar or a WAR, like the ones built by
+Spring Boot. They are exploded into the same application folder as a Leveransepakke:
+
+	lib/<artifactId>-<version>.jar  the classes and resources of the application
+	lib/*.jar                        the dependencies in BOOT-INF/lib, WEB-INF/lib and WEB-INF/lib-provided
+	metadata/openshift.json          the sidecar metadata
+
+The application is started with Start-Class in the manifest, so the Spring Boot launcher is not used.
+*/
+
+const manifestPath = "META-INF/MANIFEST.MF"
+
+// Where the classes and the class libraries are in the executable archive
+var archiveClassFolders = []string{"BOOT-INF/classes/", "WEB-INF/classes/"}
+var archiveLibraryFolders = []string{"BOOT-INF/lib/", "WEB-INF/lib/", "WEB-INF/lib-provided/"}
+
+// The launcher classes of Spring Boot. Not needed when the archive is exploded
+const springBootLoaderFolder = "org/springframework/boot/loader/"
+
+// IsExecutableArchive is true for jars and WARs. The files in a Leveransepakke are in a single root folder
+func IsExecutableArchive(archivePath string) (bool, error) {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed to open archive %s", archivePath)
+	}
+	defer zipReader.Close()
+
+	for _, zipEntry := range zipReader.File {
+		if zipEntry.Name == manifestPath {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+/*
+prepareExecutableArchive explodes the archive into the application folder and writes the metadata. It returns the
+name of the application jar, used as the prefix of it in lib/, and the main class.
+
+The metadata comes from DELIVERABLE_METADATA in the BuildConfig, or from the openshift.json deployed with
+classifier openshift next to the deliverable in Nexus.
+*/
+func prepareExecutableArchive(cfg global.Config, archivePath string, applicationFolder string, downloadArtifact nexus.ArtifactDownloader) (string, string, error) {
+	gav := cfg.ApplicationSpec.MavenGav
+	applicationJarName := "application"
+	if gav.ArtifactId != "" {
+		applicationJarName = gav.ArtifactId + "-" + gav.Version
+	}
+
+	mainClass, err := explodeExecutableArchive(archivePath, applicationFolder, applicationJarName)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to extract application archive")
+	}
+
+	metadata, err := nexus.FindSidecarMetadata(cfg, downloadArtifact)
+	if err != nil {
+		return "", "", err
+	}
+	if err := util.NewFileWriter(applicationFolder)(util.NewByteWriter(metadata), util.DeliveryMetadataPath); err != nil {
+		return "", "", errors.Wrap(err, "Failed to write application metadata")
+	}
+	return applicationJarName, mainClass, nil
+}
+
+func explodeExecutableArchive(archivePath string, applicationFolder string, applicationJarName string) (string, error) {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to open archive %s", archivePath)
+	}
+	defer zipReader.Close()
+
+	manifest, err := readManifest(&zipReader.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	libraryFolder := filepath.Join(applicationFolder, "lib")
+	if err := os.MkdirAll(libraryFolder, 0755); err != nil {
+		return "", err
+	}
+
+	mainClass := manifest["Start-Class"]
+	if mainClass == "" {
+		mainClass = manifest["Main-Class"]
+		if mainClass == "" || strings.HasPrefix(mainClass, strings.Replace(springBootLoaderFolder, "/", ".", -1)) {
+			return "", errors.New("Found no Start-Class or Main-Class in the manifest of the archive")
+		}
+		// A plain executable jar is the application jar itself
+		logrus.Infof("Using executable jar with Main-Class %s", mainClass)
+		return mainClass, util.CopyFile(archivePath, filepath.Join(libraryFolder, applicationJarName+".jar"))
+	}
+	logrus.Infof("Exploding Spring Boot archive with Start-Class %s", mainClass)
+
+	applicationJar, err := os.Create(filepath.Join(libraryFolder, applicationJarName+".jar"))
+	if err != nil {
+		return "", err
+	}
+	defer applicationJar.Close()
+	applicationJarWriter := zip.NewWriter(applicationJar)
+
+	for _, zipEntry := range zipReader.File {
+		if zipEntry.FileInfo().IsDir() {
+			continue
+		}
+		if library, ok := trimFolder(zipEntry.Name, archiveLibraryFolders); ok {
+			if err := extractLibrary(zipEntry, filepath.Join(libraryFolder, path.Base(library))); err != nil {
+				return "", errors.Wrapf(err, "Failed to extract %s", zipEntry.Name)
+			}
+			continue
+		}
+		name, ok := trimFolder(zipEntry.Name, archiveClassFolders)
+		if !ok {
+			if !isWebContent(zipEntry.Name) {
+				continue
+			}
+			// Servlet containers serve the content of META-INF/resources in the jars on the classpath
+			name = "META-INF/resources/" + zipEntry.Name
+		}
+		if err := copyZipEntry(applicationJarWriter, zipEntry, name); err != nil {
+			return "", errors.Wrapf(err, "Failed to add %s to the application jar", zipEntry.Name)
+		}
+	}
+	return mainClass, applicationJarWriter.Close()
+}
+
+// The content of a WAR outside WEB-INF and META-INF is the document root of the web application
+func isWebContent(name string) bool {
+	return !strings.HasPrefix(name, "META-INF/") && !strings.HasPrefix(name, "WEB-INF/") &&
+		!strings.HasPrefix(name, "BOOT-INF/") && !strings.HasPrefix(name, springBootLoaderFolder)
+}
+
+func trimFolder(name string, folders []string) (string, bool) {
+	for _, folder := range folders {
+		if strings.HasPrefix(name, folder) {
+			return strings.TrimPrefix(name, folder), true
+		}
+	}
+	return "", false
+}
+
+// readManifest returns the main section of the manifest. Long values are continued on lines starting with a space
+func readManifest(zipReader *zip.Reader) (map[string]string, error) {
+	for _, zipEntry := range zipReader.File {
+		if zipEntry.Name != manifestPath {
+			continue
+		}
+		reader, err := zipEntry.Open()
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to open manifest")
+		}
+		defer reader.Close()
+
+		manifest := make(map[string]string)
+		scanner := bufio.NewScanner(reader)
+		lastKey := ""
+		for scanner.Scan() {
+			line := strings.TrimRight(scanner.Text(), "\r")
+			if line == "" {
+				break
+			}
+			if strings.HasPrefix(line, " ") && lastKey != "" {
+				manifest[lastKey] += line[1:]
+				continue
+			}
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			lastKey = strings.TrimSpace(parts[0])
+			manifest[lastKey] = strings.TrimSpace(parts[1])
+		}
+		return manifest, scanner.Err()
+	}
+	return nil, errors.Errorf("Found no %s in the archive", manifestPath)
+}
+
+func extractLibrary(zipEntry *zip.File, target string) error {
+	reader, err := zipEntry.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+func copyZipEntry(writer *zip.Writer, zipEntry *zip.File, name string) error {
+	reader, err := zipEntry.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	entryWriter, err := writer.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: zipEntry.Modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entryWriter, reader)
+	return err
+}
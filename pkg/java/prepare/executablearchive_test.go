@@ -0,0 +1,132 @@
+package prepare
+
+import (
+	"archive/zip"
+	"bytes"
+	global "github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const springBootManifest = "Manifest-Version: 1.0\r\nMain-Class: org.springframework.boot.loader.WarLauncher\r\n" +
+	"Start-Class: no.skatteetaten.aurora.demo.DemoApplicati\r\n on\r\n\r\n"
+
+func TestPrepareExecutableArchive(t *testing.T) {
+	folder, err := ioutil.TempDir("", "executable-archive-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+
+	archive := filepath.Join(folder, "demo.war")
+	writeTestArchive(t, archive, map[string][]byte{
+		"META-INF/MANIFEST.MF":                                              []byte(springBootManifest),
+		"org/springframework/boot/loader/WarLauncher.class":                 classHeader(52),
+		"WEB-INF/classes/no/skatteetaten/aurora/demo/DemoApplication.class": classHeader(52),
+		"WEB-INF/classes/application.yml":                                   []byte("server.port: 8080"),
+		"WEB-INF/lib/slf4j-api-1.7.30.jar":                                  testJar(t, "org/slf4j/Logger.class"),
+		"WEB-INF/lib-provided/tomcat-embed-core-9.0.37.jar":                 testJar(t, "org/apache/catalina/Server.class"),
+		"index.html": []byte("<html></html>"),
+	})
+
+	cfg := global.Config{ApplicationSpec: global.ApplicationSpec{
+		MavenGav:            global.MavenGav{ArtifactId: "demo", Version: "1.0.0"},
+		DeliverableMetadata: `{"docker": {"maintainer": "wrench@sits.no"}}`,
+	}}
+	applicationFolder := filepath.Join(folder, "application")
+	prefix, mainClass, err := prepareExecutableArchive(cfg, archive, applicationFolder, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "demo-1.0.0", prefix)
+	assert.Equal(t, "no.skatteetaten.aurora.demo.DemoApplication", mainClass)
+
+	for _, file := range []string{"lib/slf4j-api-1.7.30.jar", "lib/tomcat-embed-core-9.0.37.jar", "metadata/openshift.json"} {
+		_, err := os.Stat(filepath.Join(applicationFolder, file))
+		assert.NoError(t, err, file)
+	}
+
+	applicationJar, err := zip.OpenReader(filepath.Join(applicationFolder, "lib", "demo-1.0.0.jar"))
+	assert.NoError(t, err)
+	defer applicationJar.Close()
+	names := make([]string, 0)
+	for _, entry := range applicationJar.File {
+		names = append(names, entry.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"no/skatteetaten/aurora/demo/DemoApplication.class",
+		"application.yml",
+		"META-INF/resources/index.html",
+	}, names)
+
+	meta, err := loadDeliverableMetadata(filepath.Join(applicationFolder, "metadata", "openshift.json"))
+	assert.NoError(t, err)
+	addManifestMainClass(meta, mainClass)
+	assert.NoError(t, verifyClassVersions(applicationFolder, meta, baseImageWithJava(nil, nil)))
+}
+
+func TestPrepareExecutableArchiveDownloadsSidecarMetadata(t *testing.T) {
+	folder, err := ioutil.TempDir("", "executable-archive-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+
+	archive := filepath.Join(folder, "demo.jar")
+	writeTestArchive(t, archive, map[string][]byte{
+		"META-INF/MANIFEST.MF":       []byte("Manifest-Version: 1.0\nMain-Class: no.skatteetaten.Main\n"),
+		"no/skatteetaten/Main.class": classHeader(52),
+	})
+	sidecar := filepath.Join(folder, "demo-1.0.0-openshift.json")
+	assert.NoError(t, ioutil.WriteFile(sidecar, []byte(`{"docker": {"maintainer": "wrench@sits.no"}}`), 0644))
+
+	var requested global.MavenGav
+	downloadArtifact := func(gav *global.MavenGav) (nexus.Deliverable, error) {
+		requested = *gav
+		return nexus.Deliverable{Path: sidecar}, nil
+	}
+
+	cfg := global.Config{ApplicationSpec: global.ApplicationSpec{
+		MavenGav: global.MavenGav{GroupId: "no.skatteetaten", ArtifactId: "demo", Version: "1.0.0", Type: global.JarPackaging},
+	}}
+	applicationFolder := filepath.Join(folder, "application")
+	_, mainClass, err := prepareExecutableArchive(cfg, archive, applicationFolder, downloadArtifact)
+	assert.NoError(t, err)
+	assert.Equal(t, "no.skatteetaten.Main", mainClass)
+	assert.Equal(t, global.MavenGav{GroupId: "no.skatteetaten", ArtifactId: "demo", Version: "1.0.0", Classifier: "openshift", Type: "json"}, requested)
+
+	copied, err := ioutil.ReadFile(filepath.Join(applicationFolder, "lib", "demo-1.0.0.jar"))
+	assert.NoError(t, err)
+	original, err := ioutil.ReadFile(archive)
+	assert.NoError(t, err)
+	assert.Equal(t, original, copied)
+}
+
+func TestIsExecutableArchive(t *testing.T) {
+	executable, err := IsExecutableArchive("testdata/minarch-1.2.22-Leveransepakke.zip")
+	assert.NoError(t, err)
+	assert.False(t, executable)
+}
+
+func writeTestArchive(t *testing.T, path string, files map[string][]byte) {
+	assert.NoError(t, ioutil.WriteFile(path, zipContent(t, files), 0644))
+}
+
+func testJar(t *testing.T, class string) []byte {
+	return zipContent(t, map[string][]byte{class: classHeader(52)})
+}
+
+func zipContent(t *testing.T, files map[string][]byte) []byte {
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write(content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func classHeader(majorVersion uint16) []byte {
+	return []byte{0xCA, 0xFE, 0xBA, 0xBE, 0, 0, byte(majorVersion >> 8), byte(majorVersion)}
+}
@@ -0,0 +1,176 @@
+package prepare
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"time"
+	"unicode/utf16"
+)
+
+/*
+A minimal reader and writer of Java keystores in the JKS format, so no keytool is needed when the truststore is built.
+Only trusted certificate entries are supported, which is all a truststore contains.
+
+The format is big endian:
+
+	magic 0xFEEDFEED, version 2, number of entries
+	per entry: tag 2, alias, creation time in ms, certificate type "X.509", length and DER of the certificate
+	SHA-1 of the password as UTF-16, the string "Mighty Aphrodite" and all the bytes above
+*/
+
+const (
+	keyStoreMagic       = 0xFEEDFEED
+	keyStoreVersion     = 2
+	trustedCertEntryTag = 2
+	privateKeyEntryTag  = 1
+	keyStoreWhitener    = "Mighty Aphrodite"
+	certificateType     = "X.509"
+)
+
+type trustedCertificate struct {
+	Alias       string
+	Created     time.Time
+	Certificate *x509.Certificate
+}
+
+func readKeyStore(reader io.Reader, password string) ([]trustedCertificate, error) {
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) < sha1.Size {
+		return nil, errors.New("Keystore is truncated")
+	}
+
+	body, digest := content[:len(content)-sha1.Size], content[len(content)-sha1.Size:]
+	if !bytes.Equal(keyStoreDigest(password, body), digest) {
+		return nil, errors.New("Keystore is corrupt or the password is wrong")
+	}
+
+	r := bytes.NewReader(body)
+	var header struct {
+		Magic   uint32
+		Version uint32
+		Count   uint32
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, errors.Wrap(err, "Failed to read keystore header")
+	}
+	if header.Magic != keyStoreMagic || header.Version != keyStoreVersion {
+		return nil, errors.Errorf("Unsupported keystore format %x version %d", header.Magic, header.Version)
+	}
+
+	certificates := make([]trustedCertificate, 0, header.Count)
+	for i := uint32(0); i < header.Count; i++ {
+		var tag uint32
+		if err := binary.Read(r, binary.BigEndian, &tag); err != nil {
+			return nil, errors.Wrap(err, "Failed to read keystore entry")
+		}
+		if tag == privateKeyEntryTag {
+			return nil, errors.New("Keystore contains private keys. Only trusted certificates are supported")
+		} else if tag != trustedCertEntryTag {
+			return nil, errors.Errorf("Unknown keystore entry type %d", tag)
+		}
+
+		alias, err := readKeyStoreString(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to read alias")
+		}
+		var created int64
+		if err := binary.Read(r, binary.BigEndian, &created); err != nil {
+			return nil, errors.Wrapf(err, "Failed to read %s", alias)
+		}
+		if _, err := readKeyStoreString(r); err != nil {
+			return nil, errors.Wrapf(err, "Failed to read certificate type of %s", alias)
+		}
+		der, err := readKeyStoreBytes(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to read certificate %s", alias)
+		}
+		certificate, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse certificate %s", alias)
+		}
+		certificates = append(certificates, trustedCertificate{
+			Alias:       alias,
+			Created:     time.Unix(0, created*int64(time.Millisecond)),
+			Certificate: certificate,
+		})
+	}
+	return certificates, nil
+}
+
+func writeKeyStore(writer io.Writer, password string, certificates []trustedCertificate) error {
+	body := new(bytes.Buffer)
+	w := bufio.NewWriter(body)
+
+	write := func(data interface{}) {
+		binary.Write(w, binary.BigEndian, data)
+	}
+	write(uint32(keyStoreMagic))
+	write(uint32(keyStoreVersion))
+	write(uint32(len(certificates)))
+	for _, certificate := range certificates {
+		if len(certificate.Alias) > 0xFFFF {
+			return errors.Errorf("Alias %s is too long", certificate.Alias)
+		}
+		write(uint32(trustedCertEntryTag))
+		write(uint16(len(certificate.Alias)))
+		w.WriteString(certificate.Alias)
+		write(certificate.Created.UnixNano() / int64(time.Millisecond))
+		write(uint16(len(certificateType)))
+		w.WriteString(certificateType)
+		write(uint32(len(certificate.Certificate.Raw)))
+		w.Write(certificate.Certificate.Raw)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(body.Bytes()); err != nil {
+		return err
+	}
+	_, err := writer.Write(keyStoreDigest(password, body.Bytes()))
+	return err
+}
+
+func keyStoreDigest(password string, body []byte) []byte {
+	hash := sha1.New()
+	for _, c := range utf16.Encode([]rune(password)) {
+		hash.Write([]byte{byte(c >> 8), byte(c)})
+	}
+	hash.Write([]byte(keyStoreWhitener))
+	hash.Write(body)
+	return hash.Sum(nil)
+}
+
+// Strings are stored as Java modified UTF-8. We only write aliases in plain ascii, where the two are the same
+func readKeyStoreString(r io.Reader) (string, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return "", err
+	}
+	value := make([]byte, length)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+func readKeyStoreBytes(r io.Reader) ([]byte, error) {
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	value := make([]byte, length)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
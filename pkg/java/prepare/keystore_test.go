@@ -0,0 +1,25 @@
+package prepare
+
+import (
+	"bytes"
+	"github.com/skatteetaten/architect/pkg/java/prepare/resources"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReadAndWriteKeyStore(t *testing.T) {
+	cacerts, err := resources.Asset("cacerts")
+	assert.NoError(t, err)
+
+	certificates, err := readKeyStore(bytes.NewReader(cacerts), "changeit")
+	assert.NoError(t, err)
+	assert.Len(t, certificates, 118)
+	assert.Equal(t, "digicertassuredidrootca", certificates[0].Alias)
+
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, writeKeyStore(buffer, "changeit", certificates))
+	assert.Equal(t, cacerts, buffer.Bytes())
+
+	_, err = readKeyStore(bytes.NewReader(cacerts), "wrong")
+	assert.EqualError(t, err, "Keystore is corrupt or the password is wrong")
+}
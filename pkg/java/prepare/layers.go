@@ -0,0 +1,138 @@
+package prepare
+
+import (
+	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Where in the build folder the image layers are put
+	LayersFolder = "layers"
+
+	DependenciesLayer         = "dependencies"
+	SnapshotDependenciesLayer = "snapshot-dependencies"
+	ApplicationLayer          = "application"
+)
+
+// The layers in the order they are copied into the image. The most stable content goes first, so
+// a new release only uploads the layers that actually changed.
+var ImageLayers = []string{DependenciesLayer, SnapshotDependenciesLayer, ApplicationLayer}
+
+// Jars from these groups in repo/ are built by us, and change as often as the application itself
+var internalGroupPaths = []string{"no/skatteetaten/", "ske/"}
+
+var classLibraryFolders = []string{"lib/", "repo/"}
+
+// The permissions of the folders and files in the layers
+const (
+	layerDirMode  os.FileMode = 0755
+	layerFileMode os.FileMode = 0644
+)
+
+/*
+splitIntoLayers moves the extracted application in applicationRoot/application into one folder per layer in
+layersFolder, and removes applicationRoot. Each layer keeps the "application" folder, so every layer is copied to $HOME in the image.
+The permissions are set in the build context, so no RUN chmod is needed to fix them in the image.
+
+applicationJarPrefix is the name of the root folder in the deliverable, e.g. minarch-1.2.22. It is
+used to recognize our own jar in lib/.
+*/
+func splitIntoLayers(applicationRoot string, layersFolder string, applicationJarPrefix string) error {
+	applicationFolder := filepath.Join(applicationRoot, util.ApplicationFolder)
+
+	for _, layer := range ImageLayers {
+		if err := os.MkdirAll(filepath.Join(layersFolder, layer, util.ApplicationFolder), layerDirMode); err != nil {
+			return errors.Wrapf(err, "Failed to create layer %s", layer)
+		}
+	}
+
+	err := filepath.Walk(applicationFolder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(applicationFolder, path)
+		if err != nil {
+			return err
+		}
+		if relativePath == "." {
+			return nil
+		}
+
+		layer := findLayer(filepath.ToSlash(relativePath), applicationJarPrefix)
+		target := filepath.Join(layersFolder, layer, util.ApplicationFolder, relativePath)
+
+		if info.IsDir() {
+			// Keep empty folders in the application layer. Folders with content are created when the files are moved
+			if entries, err := ioutil.ReadDir(path); err == nil && len(entries) == 0 {
+				return os.MkdirAll(target, layerDirMode)
+			}
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), layerDirMode); err != nil {
+			return err
+		}
+		return os.Rename(path, target)
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to split application into layers")
+	}
+
+	if err := os.RemoveAll(applicationRoot); err != nil {
+		return errors.Wrap(err, "Failed to remove extracted application")
+	}
+
+	return setLayerPermissions(layersFolder)
+}
+
+func findLayer(relativePath string, applicationJarPrefix string) string {
+	if strings.HasPrefix(relativePath, AgentsFolder+"/") {
+		if strings.Contains(relativePath, "SNAPSHOT") {
+			return SnapshotDependenciesLayer
+		}
+		return DependenciesLayer
+	}
+	if !strings.HasSuffix(relativePath, ".jar") || !isClassLibrary(relativePath) {
+		return ApplicationLayer
+	}
+	fileName := filepath.Base(relativePath)
+	if applicationJarPrefix != "" && strings.HasPrefix(fileName, applicationJarPrefix) {
+		return ApplicationLayer
+	}
+	if strings.Contains(fileName, "SNAPSHOT") {
+		return SnapshotDependenciesLayer
+	}
+	for _, group := range internalGroupPaths {
+		if strings.HasPrefix(relativePath, "repo/"+group) {
+			return SnapshotDependenciesLayer
+		}
+	}
+	return DependenciesLayer
+}
+
+func isClassLibrary(relativePath string) bool {
+	for _, folder := range classLibraryFolders {
+		if strings.HasPrefix(relativePath, folder) {
+			return true
+		}
+	}
+	return false
+}
+
+func setLayerPermissions(layersFolder string) error {
+	return filepath.Walk(layersFolder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		if info.IsDir() {
+			return os.Chmod(path, layerDirMode)
+		}
+		return os.Chmod(path, layerFileMode)
+	})
+}
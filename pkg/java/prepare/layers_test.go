@@ -0,0 +1,19 @@
+package prepare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindLayer(t *testing.T) {
+	assert.Equal(t, ApplicationLayer, findLayer("lib/minarch-1.2.22.jar", "minarch-1.2.22"))
+	assert.Equal(t, ApplicationLayer, findLayer("metadata/openshift.json", "minarch-1.2.22"))
+	assert.Equal(t, ApplicationLayer, findLayer("bin/start.sh", "minarch-1.2.22"))
+	assert.Equal(t, DependenciesLayer, findLayer("lib/slf4j-api-1.7.6.jar", "minarch-1.2.22"))
+	assert.Equal(t, DependenciesLayer, findLayer("repo/org/slf4j/slf4j-api/1.7.6/slf4j-api-1.7.6.jar", "minarch-1.2.22"))
+	assert.Equal(t, SnapshotDependenciesLayer, findLayer("lib/aurora-utils-1.0.0-SNAPSHOT.jar", "minarch-1.2.22"))
+	assert.Equal(t, SnapshotDependenciesLayer, findLayer("repo/no/skatteetaten/aurora/utils/1.0.0/utils-1.0.0.jar", "minarch-1.2.22"))
+	assert.Equal(t, DependenciesLayer, findLayer("agents/opentelemetry-javaagent-1.2.0.jar", "minarch-1.2.22"))
+	assert.Equal(t, SnapshotDependenciesLayer, findLayer("agents/apm-agent-1.0.0-SNAPSHOT.jar", "minarch-1.2.22"))
+}
@@ -1,7 +1,6 @@
 package prepare
 
 import (
-	"fmt"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/skatteetaten/architect/pkg/config"
@@ -9,19 +8,24 @@ import (
 	"github.com/skatteetaten/architect/pkg/docker"
 	deliverable "github.com/skatteetaten/architect/pkg/java/config"
 	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/skatteetaten/architect/pkg/templates"
 	"github.com/skatteetaten/architect/pkg/util"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 )
 
 type FileGenerator interface {
 	Write(writer io.Writer) error
 }
 
-func Prepare(dockerSpec config.DockerSpec, auroraVersions *runtime.AuroraVersion, deliverable nexus.Deliverable, baseImage runtime.BaseImage) (string, error) {
+func Prepare(cfg config.Config, auroraVersions *runtime.AuroraVersion, deliverable nexus.Deliverable, baseImage runtime.BaseImage,
+	downloadArtifact nexus.ArtifactDownloader) (string, error) {
+
+	dockerSpec := cfg.DockerSpec
 
 	// Create docker build folder
 	dockerBuildPath, err := ioutil.TempDir("", "deliverable")
@@ -30,12 +34,33 @@ func Prepare(dockerSpec config.DockerSpec, auroraVersions *runtime.AuroraVersion
 		return "", errors.Wrap(err, "Failed to create root folder of Docker context")
 	}
 
+	executableArchive, err := IsExecutableArchive(deliverable.Path)
+
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to read application archive")
+	}
+
 	// Unzip deliverable
 	applicationFolder := filepath.Join(dockerBuildPath, util.ApplicationBuildFolder)
-	err = util.ExtractAndRenameDeliverable(dockerBuildPath, deliverable.Path)
+	var applicationJarPrefix, mainClass string
+	if executableArchive {
+		applicationJarPrefix, mainClass, err = prepareExecutableArchive(cfg, deliverable.Path, applicationFolder, downloadArtifact)
 
-	if err != nil {
-		return "", errors.Wrap(err, "Failed to extract application archive")
+		if err != nil {
+			return "", err
+		}
+	} else {
+		err = util.ExtractAndRenameDeliverable(dockerBuildPath, deliverable.Path)
+
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to extract application archive")
+		}
+
+		applicationJarPrefix, err = util.DeliverableRootFolder(deliverable.Path)
+
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to read application archive")
+		}
 	}
 
 	// Load metadata
@@ -45,36 +70,112 @@ func Prepare(dockerSpec config.DockerSpec, auroraVersions *runtime.AuroraVersion
 		return "", errors.Wrap(err, "Failed to read application metadata")
 	}
 
-	fileWriter := util.NewFileWriter(dockerBuildPath)
+	if mainClass != "" {
+		addManifestMainClass(meta, mainClass)
+	}
+
+	addBaseImageLabels(meta, baseImage)
+	if meta.Docker != nil {
+		meta.Docker.AddBaseImageDefaults(baseImage)
+	}
+
+	meta.BuildInfo, err = findBuildInfo(applicationFolder, applicationJarPrefix)
+
+	if err != nil {
+		return "", err
+	}
 
-	if architecture, exists := baseImage.ImageInfo.Labels["www.skatteetaten.no-imageArchitecture"]; exists && architecture == "java" {
+	if err := verifyClassVersions(applicationFolder, meta, baseImage); err != nil {
+		return "", err
+	}
+
+	if err := prepareJavaAgents(meta, downloadArtifact, applicationFolder); err != nil {
+		return "", err
+	}
 
-		logrus.Info("Running radish build")
-		if err := fileWriter(newRadishDescriptor(meta, filepath.Join(util.DockerBasedir, util.ApplicationFolder)), "radish.json"); err != nil {
-			return "", errors.Wrap(err, "Unable to create radish descriptor")
+	if err := prepareDockerExtensions(meta, applicationFolder, dockerBuildPath); err != nil {
+		return "", err
+	}
+
+	architecture, err := findImageArchitecture(baseImage)
+
+	if err != nil {
+		return "", err
+	}
+
+	dockerSpec.Templates, err = templates.Load(dockerSpec.TemplateDir, baseImage.GetTemplateVersion())
+
+	if err != nil {
+		return "", err
+	}
+	dockerSpec.HealthCheckTool = baseImage.GetHealthCheckTool()
+
+	fileWriter := util.NewFileWriter(dockerBuildPath)
+
+	dockerSpec.CaCertificates = findCaCertificates(dockerSpec.CaCertificates, meta)
+	if dockerSpec.CaCertificates.Enabled() {
+		if err := prepareCaCertificates(dockerSpec.CaCertificates, fileWriter); err != nil {
+			return "", err
 		}
-		if err = fileWriter(NewRadishDockerFile(dockerSpec, *auroraVersions, *meta, baseImage.DockerImage, docker.GetUtcTimestamp()),
-			"Dockerfile"); err != nil {
-			return "", errors.Wrap(err, "Failed to create Dockerfile")
+		if meta.Java != nil {
+			meta.Java.JvmOpts = strings.TrimSpace(meta.Java.JvmOpts + " " + trustStoreJavaOptions())
 		}
-		//TODO: Hack. Remove code later
-	} else if architecture, exists := baseImage.ImageInfo.Labels["www.skatteetaten.no-imageArchitecture"]; exists && architecture == "java-test" {
-		logrus.Info("Running test image build")
+	}
 
-		if err := fileWriter(newRadishDescriptor(meta, filepath.Join(util.DockerBasedir, util.ApplicationFolder)), "radish.json"); err != nil {
-			return "", errors.Wrap(err, "Unable to create radish descriptor")
-		}
-		if err = fileWriter(NewRadishTestImageDockerFile(dockerSpec, *auroraVersions, *meta, baseImage.DockerImage, docker.GetUtcTimestamp()),
-			"Dockerfile"); err != nil {
-			return "", errors.Wrap(err, "Failed to create Dockerfile")
+	layersFolder := filepath.Join(dockerBuildPath, LayersFolder)
+	applicationRoot := filepath.Join(dockerBuildPath, util.DockerfileApplicationFolder)
+
+	logrus.Infof("Running %s build", architecture.Name)
+	if !dockerSpec.Templates.Legacy() {
+		if err := splitIntoLayers(applicationRoot, layersFolder, applicationJarPrefix); err != nil {
+			return "", err
 		}
-	} else {
-		return "", fmt.Errorf("The base image provided does not support radish. Make sure you use the latest version")
+	}
+	if err := fileWriter(architecture.Descriptor(meta, filepath.Join(util.DockerBasedir, util.ApplicationFolder)), architecture.DescriptorFile); err != nil {
+		return "", errors.Wrap(err, "Unable to create radish descriptor")
+	}
+	if err = fileWriter(architecture.Dockerfile(dockerSpec, *auroraVersions, *meta, baseImage.DockerImage, docker.GetUtcTimestamp()),
+		"Dockerfile"); err != nil {
+		return "", errors.Wrap(err, "Failed to create Dockerfile")
 	}
 
 	return dockerBuildPath, nil
 }
 
+// The main class in the metadata has precedence over the one in the manifest
+func addManifestMainClass(meta *deliverable.DeliverableMetadata, mainClass string) {
+	if meta.Java == nil {
+		meta.Java = &deliverable.MetadataJava{}
+	}
+	if meta.Java.MainClass == "" {
+		meta.Java.MainClass = mainClass
+	}
+}
+
+// Records which base image was used, and whether it was chosen in the BuildConfig or in the deliverable
+func addBaseImageLabels(meta *deliverable.DeliverableMetadata, baseImage runtime.BaseImage) {
+	if meta.Docker == nil || baseImage.Source == "" {
+		return
+	}
+	if meta.Docker.Labels == nil {
+		meta.Docker.Labels = make(map[string]string)
+	}
+	meta.Docker.Labels[docker.LABEL_BASE_IMAGE] = baseImage.Repository + ":" + baseImage.Tag
+	meta.Docker.Labels[docker.LABEL_BASE_IMAGE_SOURCE] = baseImage.Source
+}
+
+// The files copied by docker.extensions are put in the build folder before the application is split into layers
+func prepareDockerExtensions(meta *deliverable.DeliverableMetadata, applicationFolder string, dockerBuildPath string) error {
+	if meta.Docker == nil {
+		return nil
+	}
+	extensions, err := docker.NewDockerExtensions(meta.Docker.Extensions)
+	if err != nil {
+		return err
+	}
+	return extensions.CopyFiles(applicationFolder, dockerBuildPath)
+}
+
 func loadDeliverableMetadata(metafile string) (*deliverable.DeliverableMetadata, error) {
 	fileExists, err := util.Exists(metafile)
 
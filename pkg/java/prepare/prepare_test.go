@@ -7,6 +7,7 @@ import (
 	"github.com/skatteetaten/architect/pkg/nexus"
 	"github.com/skatteetaten/architect/pkg/util"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -19,7 +20,7 @@ func TestPrepare(t *testing.T) {
 		"2.0.0",
 		"2.0.0-b1.11.0-oracle8-1.0.2")
 
-	dockerBuildPath, err := prepare.Prepare(global.DockerSpec{}, auroraVersions,
+	dockerBuildPath, err := prepare.Prepare(global.Config{}, auroraVersions,
 		nexus.Deliverable{Path: "testdata/minarch-1.2.22-Leveransepakke.zip"},
 		runtime.BaseImage{
 			DockerImage: runtime.DockerImage{
@@ -29,15 +30,23 @@ func TestPrepare(t *testing.T) {
 			ImageInfo: &runtime.ImageInfo{
 				CompleteBaseImageVersion: "hei",
 				Enviroment:               make(map[string]string),
-				Labels:                   map[string]string{"www.skatteetaten.no-imageArchitecture": "java"},
+				Labels: map[string]string{
+					"www.skatteetaten.no-imageArchitecture": "java",
+					runtime.TemplateVersionLabel:            "2",
+				},
 			},
-		})
+			Source: "deliverable",
+		}, nil)
 
 	assert.NoError(t, err)
 
 	// Dockerfile
 	filePath := filepath.Join(dockerBuildPath, "Dockerfile")
 	fileExists, err := util.Exists(filePath)
+	dockerfile, err := ioutil.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(dockerfile), `www.skatteetaten.no-baseImage="test:1"`)
+	assert.Contains(t, string(dockerfile), `www.skatteetaten.no-baseImageSource="deliverable"`)
 
 	//radish
 	filePath = filepath.Join(dockerBuildPath, "radish.json")
@@ -49,16 +58,59 @@ func TestPrepare(t *testing.T) {
 		t.Errorf("Expected file %s not found", filePath)
 	}
 
-	// Application
-	applicationPath := filepath.Join(dockerBuildPath, "app", "application")
-	applicationExists, err := util.Exists(applicationPath)
+	// Application layers
+	for _, layerFile := range []string{
+		"dependencies/application/lib/slf4j-api-1.7.6.jar",
+		"dependencies/application/lib/log4j-over-slf4j-1.7.6.jar",
+		"application/application/lib/minarch-1.2.22.jar",
+		"application/application/metadata/openshift.json",
+	} {
+		filePath = filepath.Join(dockerBuildPath, "layers", layerFile)
+		fileExists, err = util.Exists(filePath)
 
-	if err != nil {
-		t.Error(err)
-	} else if !applicationExists {
-		t.Errorf("Expected file %s not found", filePath)
+		if err != nil {
+			t.Error(err)
+		} else if !fileExists {
+			t.Errorf("Expected file %s not found", filePath)
+		}
 	}
 
+	info, err := os.Stat(filepath.Join(dockerBuildPath, "layers", "dependencies", "application", "lib", "slf4j-api-1.7.6.jar"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	extractedExists, err := util.Exists(filepath.Join(dockerBuildPath, "app"))
+	assert.NoError(t, err)
+	assert.False(t, extractedExists)
+
 	os.RemoveAll(dockerBuildPath)
 
 }
+
+func TestPrepareWithLegacyTemplates(t *testing.T) {
+	auroraVersions := runtime.NewAuroraVersion("2.0.0", true, "2.0.0", "2.0.0-b1.11.0-oracle8-1.0.2")
+
+	dockerBuildPath, err := prepare.Prepare(global.Config{}, auroraVersions,
+		nexus.Deliverable{Path: "testdata/minarch-1.2.22-Leveransepakke.zip"},
+		runtime.BaseImage{
+			DockerImage: runtime.DockerImage{Repository: "test", Tag: "1"},
+			ImageInfo: &runtime.ImageInfo{
+				Enviroment: make(map[string]string),
+				Labels:     map[string]string{"www.skatteetaten.no-imageArchitecture": "java"},
+			},
+		}, nil)
+	assert.NoError(t, err)
+	defer os.RemoveAll(dockerBuildPath)
+
+	dockerfile, err := ioutil.ReadFile(filepath.Join(dockerBuildPath, "Dockerfile"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(dockerfile), "COPY ./app radish.json $HOME/\n")
+
+	extractedExists, err := util.Exists(filepath.Join(dockerBuildPath, "app", "application", "lib", "minarch-1.2.22.jar"))
+	assert.NoError(t, err)
+	assert.True(t, extractedExists)
+
+	layersExist, err := util.Exists(filepath.Join(dockerBuildPath, "layers"))
+	assert.NoError(t, err)
+	assert.False(t, layersExist)
+}
@@ -0,0 +1,38 @@
+package prepare
+
+import (
+	"archive/zip"
+	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/util"
+)
+
+/*
+DeliverableVersion returns the version the deliverable was built with. It is Implementation-Version in the manifest
+of an executable jar or WAR, and the version in the name of the root folder of a Leveransepakke. The version is
+empty if the deliverable does not tell it.
+*/
+func DeliverableVersion(archivePath string, artifactId string) (string, error) {
+	executableArchive, err := IsExecutableArchive(archivePath)
+	if err != nil {
+		return "", err
+	}
+	if !executableArchive {
+		rootFolder, err := util.DeliverableRootFolder(archivePath)
+		if err != nil {
+			return "", err
+		}
+		return util.DeliverableVersion(rootFolder, artifactId), nil
+	}
+
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to open archive %s", archivePath)
+	}
+	defer zipReader.Close()
+
+	manifest, err := readManifest(&zipReader.Reader)
+	if err != nil {
+		return "", err
+	}
+	return manifest["Implementation-Version"], nil
+}
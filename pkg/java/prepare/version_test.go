@@ -0,0 +1,28 @@
+package prepare
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliverableVersion(t *testing.T) {
+	version, err := DeliverableVersion("testdata/minarch-1.2.22-Leveransepakke.zip", "minarch")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.22", version)
+
+	folder, err := ioutil.TempDir("", "version")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+
+	archive := filepath.Join(folder, "demo-1.0.0.jar")
+	writeTestArchive(t, archive, map[string][]byte{
+		"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\r\nImplementation-Version: 1.4.0\r\nStart-Class: no.skatteetaten.Demo\r\n\r\n"),
+	})
+	version, err = DeliverableVersion(archive, "demo")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.4.0", version)
+}
@@ -60,13 +60,15 @@ func (n *NexusDownloader) DownloadArtifact(c *config.MavenGav, na *config.NexusA
 
 	// Detect API version
 	useNexus3 := false
+	if n.baseUrl == "" {
+		return deliverable, errors.New("No Nexus url configured")
+	}
 	resp, err := http.Get(n.baseUrl)
 	if err != nil {
-		logrus.Fatalf("Failed response from %s, error: %s", n.baseUrl, err)
-	} else {
-		useNexus3, _ = regexp.MatchString(`^Nexus/3\..*$`, resp.Header.Get("Server"))
-		logrus.Infof("Use nexus 3: %t", useNexus3)
+		return deliverable, errors.Wrapf(err, "Failed response from %s", n.baseUrl)
 	}
+	useNexus3, _ = regexp.MatchString(`^Nexus/3\..*$`, resp.Header.Get("Server"))
+	logrus.Infof("Use nexus 3: %t", useNexus3)
 	defer resp.Body.Close()
 
 	resourceUrl, err := n.resourceURL(c, useNexus3)
@@ -143,7 +145,11 @@ func (n *NexusDownloader) DownloadArtifact(c *config.MavenGav, na *config.NexusA
 */
 func GetSnapshotTimestampVersion(gav config.MavenGav, deliverable Deliverable) string {
 	if gav.IsSnapshot() {
-		replacer := strings.NewReplacer(gav.ArtifactId+"-", "", "-"+string(gav.Classifier)+"."+string(gav.Type), "")
+		suffix := "." + string(gav.Type)
+		if gav.Classifier != "" {
+			suffix = "-" + string(gav.Classifier) + suffix
+		}
+		replacer := strings.NewReplacer(gav.ArtifactId+"-", "", suffix, "")
 		version := "SNAPSHOT-" + replacer.Replace(path.Base(deliverable.Path))
 		return version
 	}
@@ -88,6 +88,24 @@ func TestDownloadFromNexus3Server(t *testing.T) {
 	}
 }
 
+func TestDownloadWithoutNexusFails(t *testing.T) {
+	m := config.MavenGav{
+		ArtifactId: "openshift-resource-monitor",
+		GroupId:    "ske.fellesplattform.monitor",
+		Version:    "1.1.4",
+	}
+
+	if _, err := NewNexusDownloader("").DownloadArtifact(&m, nil); err == nil {
+		t.Error("expected an error without a Nexus url")
+	}
+	if _, err := NewNexusDownloader("http://localhost:0").DownloadArtifact(&m, nil); err == nil {
+		t.Error("expected an error when Nexus does not respond")
+	}
+	if NewArtifactDownloader(nil, nil) != nil {
+		t.Error("expected no artifact downloader without a downloader")
+	}
+}
+
 func TestNewLocalDownloader(t *testing.T) {
 	d := NewBinaryDownloader("test")
 	m := config.MavenGav{
@@ -0,0 +1,46 @@
+package nexus
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/skatteetaten/architect/pkg/config"
+	"io/ioutil"
+)
+
+// The classifier of the openshift.json deployed next to a deliverable without metadata, like an executable jar
+const MetadataClassifier = "openshift"
+
+// ArtifactDownloader downloads artifacts besides the deliverable, like Java agents and the sidecar metadata
+type ArtifactDownloader func(gav *config.MavenGav) (Deliverable, error)
+
+// NewArtifactDownloader downloads with the same Nexus access as the deliverable. It is nil without a downloader
+func NewArtifactDownloader(downloader Downloader, nexusAccess *config.NexusAccess) ArtifactDownloader {
+	if downloader == nil {
+		return nil
+	}
+	return func(gav *config.MavenGav) (Deliverable, error) {
+		return downloader.DownloadArtifact(gav, nexusAccess)
+	}
+}
+
+// FindSidecarMetadata returns DELIVERABLE_METADATA in the BuildConfig, or downloads the openshift.json deployed with
+// classifier openshift next to the deliverable in Nexus
+func FindSidecarMetadata(cfg config.Config, downloadArtifact ArtifactDownloader) ([]byte, error) {
+	if cfg.ApplicationSpec.DeliverableMetadata != "" {
+		logrus.Info("Using the deliverable metadata in the BuildConfig")
+		return []byte(cfg.ApplicationSpec.DeliverableMetadata), nil
+	}
+	if downloadArtifact == nil || cfg.ApplicationSpec.MavenGav.ArtifactId == "" {
+		return nil, errors.New("No metadata for the deliverable. Set DELIVERABLE_METADATA in the BuildConfig")
+	}
+
+	gav := cfg.ApplicationSpec.MavenGav
+	gav.Classifier = MetadataClassifier
+	gav.Type = "json"
+	logrus.Infof("Downloading the deliverable metadata %s:%s:%s:%s", gav.GroupId, gav.ArtifactId, gav.Version, gav.Classifier)
+	sidecar, err := downloadArtifact(&gav)
+	if err != nil {
+		return nil, errors.Wrap(err, "No metadata for the deliverable. Deploy openshift.json with classifier openshift, or set DELIVERABLE_METADATA in the BuildConfig")
+	}
+	return ioutil.ReadFile(sidecar.Path)
+}
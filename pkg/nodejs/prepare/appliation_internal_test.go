@@ -14,7 +14,7 @@ import (
 const buildTime = "2016-09-12T14:30:10Z"
 const expectedNodeJsDockerFile = `FROM aurora/wrench:latest
 
-LABEL maintainer="Oyvind <oyvind@dagobah.wars>" version="1.2.3"
+LABEL maintainer="Oyvind <oyvind@dagobah.wars>" org.opencontainers.image.created="2016-09-12T14:30:10Z" org.opencontainers.image.vendor="Skatteetaten" org.opencontainers.image.version="1.2.3" version="1.2.3"
 
 COPY ./package /u01/application
 
@@ -36,7 +36,7 @@ CMD ["/u01/bin/run_nginx"]`
 
 const expectedRadishNodeJsDockerFile = `FROM aurora/wrench:latest
 
-LABEL maintainer="Oyvind <oyvind@dagobah.wars>" version="1.2.3"
+LABEL maintainer="Oyvind <oyvind@dagobah.wars>" org.opencontainers.image.created="2016-09-12T14:30:10Z" org.opencontainers.image.vendor="Skatteetaten" org.opencontainers.image.version="1.2.3" version="1.2.3"
 
 COPY ./package /u01/application
 
@@ -58,7 +58,7 @@ CMD ["/u01/bin/run_nginx"]`
 
 const expectedNodeJsDockerFileWithoutNodeApp = `FROM aurora/wrench:latest
 
-LABEL maintainer="Oyvind <oyvind@dagobah.wars>" version="1.2.3"
+LABEL maintainer="Oyvind <oyvind@dagobah.wars>" org.opencontainers.image.created="2016-09-12T14:30:10Z" org.opencontainers.image.vendor="Skatteetaten" org.opencontainers.image.version="1.2.3" version="1.2.3"
 
 COPY ./package /u01/application
 
@@ -80,7 +80,7 @@ CMD ["/u01/bin/run_nginx"]`
 
 const expectedRadishNodeJsDockerFileWithoutNodeApp = `FROM aurora/wrench:latest
 
-LABEL maintainer="Oyvind <oyvind@dagobah.wars>" version="1.2.3"
+LABEL maintainer="Oyvind <oyvind@dagobah.wars>" org.opencontainers.image.created="2016-09-12T14:30:10Z" org.opencontainers.image.vendor="Skatteetaten" org.opencontainers.image.version="1.2.3" version="1.2.3"
 
 COPY ./package /u01/application
 
@@ -449,6 +449,66 @@ func TestThatOverridesIsSetInRadishConfig(t *testing.T) {
 	})
 }
 
+func TestThatUnsupportedImageArchitectureFails(t *testing.T) {
+	files := make(map[string]string)
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &osJson, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	},
+		ImageInfo: &runtime.ImageInfo{
+			Labels: map[string]string{"www.skatteetaten.no-imageArchitecture": "java"},
+		}}, auroraVersion, testFileWriter(files), buildTime)
+	assert.EqualError(t, err, `The base image provided has an unsupported www.skatteetaten.no-imageArchitecture label "java". Supported values are [nodejs]`)
+	assert.Empty(t, files)
+}
+
+func TestThatUnsupportedTemplateVersionFails(t *testing.T) {
+	files := make(map[string]string)
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &osJson, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	},
+		ImageInfo: &runtime.ImageInfo{
+			Labels: map[string]string{
+				"www.skatteetaten.no-imageArchitecture": "nodejs",
+				"www.skatteetaten.no-templateVersion":   "3",
+			},
+		}}, auroraVersion, testFileWriter(files), buildTime)
+	assert.EqualError(t, err, "Template version 3 of the base image is not supported. Supported versions are 1, 2")
+	assert.Empty(t, files)
+}
+
+func TestThatLegacyTemplatesRejectNewFeatures(t *testing.T) {
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	proxies := osJson
+	proxies.Aurora.Proxies = []nginxProxy{{Path: "/orders", Upstream: "ORDERS"}}
+	webapps := osJson
+	webapps.Aurora = auroraApplication{
+		Webapp:  &webApplication{StaticContent: "app"},
+		Webapps: []webApplication{{StaticContent: "docs", Path: "docs"}},
+	}
+	for _, test := range []struct {
+		json   openshiftJson
+		labels map[string]string
+		err    string
+	}{
+		{json: proxies, labels: map[string]string{}, err: "Unable to create nginx.conf: proxies in openshift.json"},
+		{json: webapps, labels: map[string]string{"www.skatteetaten.no-imageArchitecture": "nodejs"},
+			err: "More than one web application in openshift.json"},
+	} {
+		files := make(map[string]string)
+		err := prepareImage(config.DockerSpec{}, &test.json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+			Tag:        "latest",
+			Repository: "aurora/wrench",
+		}, ImageInfo: &runtime.ImageInfo{
+			Labels: test.labels,
+		}}, auroraVersion, testFileWriter(files), buildTime)
+		assert.EqualError(t, err, test.err+" is not supported by template version 1. Use a base image with template version 2")
+	}
+}
+
 func testFileWriter(files map[string]string) util.FileWriter {
 	return func(writer util.WriterFunc, filename ...string) error {
 		buffer := new(bytes.Buffer)
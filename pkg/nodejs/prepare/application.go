@@ -1,7 +1,10 @@
 package prepare
 
 import (
-	"regexp"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -11,28 +14,28 @@ import (
 	"github.com/skatteetaten/architect/pkg/docker"
 	"github.com/skatteetaten/architect/pkg/nexus"
 	process "github.com/skatteetaten/architect/pkg/process/build"
+	"github.com/skatteetaten/architect/pkg/templates"
 	"github.com/skatteetaten/architect/pkg/util"
 )
 
-/*
-
-We sanitize the input.... Don't want to large inputs.
-
-For example; Accepting very large client_max_body_size would make a DOS attack very easy to implement...
-
-*/
-var allowedNginxOverrides = map[string]func(string) error{
-	"client_max_body_size": func(s string) error {
-		// between 1 and 20
-		match, err := regexp.MatchString("^([1-9]|[1-4][0-9]|[5][0])m$", s)
-		if err != nil {
-			return err
-		}
-		if !match {
-			return errors.New("Value on client_max_body_size should be on the form Nm where N is between 1 and 50")
-		}
-		return nil
-	},
+func init() {
+	process.RegisterApplicationType(process.ApplicationTypeSpec{
+		// PACKAGING is ignored. The deliverable is always a tarball
+		Config: config.ApplicationTypeSpec{
+			Type:       config.NodeJsLeveransepakke,
+			Names:      []string{"nodejs"},
+			Classifier: config.Webleveransepakke,
+			Packaging:  config.TgzPackaging,
+			Archives:   []config.PackageType{config.TgzPackaging},
+		},
+		Name: "Webleveranse",
+		// The nodejs build downloads nothing besides the deliverable
+		Prepper: func(nexus.Downloader) process.Prepper {
+			return Prepper()
+		},
+		VersionReader: VersionReader(),
+		// No MetadataLoader. openshift.json is validated as soon as it is read when the tarball is extracted in one pass
+	})
 }
 
 func Prepper() process.Prepper {
@@ -57,6 +60,21 @@ func Prepper() process.Prepper {
 	}
 }
 
+// VersionReader reads the version in package.json
+func VersionReader() process.VersionReader {
+	return func(cfg *config.Config, deliverable nexus.Deliverable) (string, error) {
+		content, err := readFileInTarball(deliverable.Path, packageJsonInTarball)
+		if err != nil || content == nil {
+			return "", err
+		}
+		var p packageJson
+		if err := json.Unmarshal(content, &p); err != nil {
+			return "", errors.Wrap(err, "Error reading package.json")
+		}
+		return p.Version, nil
+	}
+}
+
 /*
 func prepare(dockerSpec config.DockerSpec, c config.ApplicationSpec, auroraVersion *runtime.AuroraVersion,
 	deliverable nexus.Deliverable, baseImage runtime.DockerImage) ([]PreparedImage, error) {*/
@@ -64,13 +82,35 @@ func prepare(cfg config.Config, auroraVersion *runtime.AuroraVersion,
 	deliverable nexus.Deliverable, baseImage runtime.BaseImage) ([]PreparedImage, error) {
 	logrus.Debugf("Building %s", cfg.ApplicationSpec.MavenGav.Name())
 
-	openshiftJson, err := findOpenshiftJsonInTarball(deliverable.Path)
+	pathToApplication, err := ioutil.TempDir("", "nodejs-architect")
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create root folder of Docker context")
+	}
+
+	openshiftJson, err := extractTarball(deliverable.Path, pathToApplication)
 	if err != nil {
+		os.RemoveAll(pathToApplication)
 		return nil, err
 	}
 
-	pathToApplication, err := extractTarball(deliverable.Path)
+	openshiftJson.BuildInfo, err = readBuildInfo(pathToApplication)
 	if err != nil {
+		os.RemoveAll(pathToApplication)
+		return nil, err
+	}
+
+	extensions, err := docker.NewDockerExtensions(openshiftJson.DockerMetadata.Extensions)
+	if err == nil {
+		err = extensions.CopyFiles(filepath.Join(pathToApplication, "package"), pathToApplication)
+	}
+	if err != nil {
+		os.RemoveAll(pathToApplication)
+		return nil, err
+	}
+
+	openshiftJson.ServeBrotli, err = precompressWebApps(filepath.Join(pathToApplication, "package"), openshiftJson.Aurora)
+	if err != nil {
+		os.RemoveAll(pathToApplication)
 		return nil, err
 	}
 
@@ -89,36 +129,43 @@ func prepare(cfg config.Config, auroraVersion *runtime.AuroraVersion,
 func prepareImage(dockerSpec config.DockerSpec, v *openshiftJson, baseImage runtime.BaseImage, auroraVersion *runtime.AuroraVersion, writer util.FileWriter,
 	imageBuildTime string) error {
 	completeDockerName := baseImage.GetCompleteDockerTagName()
+	v.DockerMetadata.AddBaseImageDefaults(baseImage)
+	if v.ServeBrotli && !baseImage.HasNginxBrotli() {
+		logrus.Warnf("The base image does not have the label %s=true. The .br files are not served", runtime.NginxBrotliLabel)
+		v.ServeBrotli = false
+	}
 	nginxData, dockerData, err := mapOpenShiftJsonToTemplateInput(dockerSpec, v, completeDockerName, imageBuildTime, auroraVersion)
 
 	if err != nil {
 		return errors.Wrap(err, "Error processing AuroraConfig")
 	}
 
-	if architecture, exists := baseImage.ImageInfo.Labels["www.skatteetaten.no-imageArchitecture"]; exists && architecture == "nodejs" {
-
-		logrus.Info("Running radish nodejs build")
-
-		if err := writer(newRadishNginxConfig(dockerData, nginxData), "nginx-radish.json"); err != nil {
-			return errors.Wrap(err, "Unable to create radish-nginx configuration")
-		}
+	architecture, err := findImageArchitecture(baseImage)
+	if err != nil {
+		return err
+	}
 
-		err = writer(util.NewTemplateWriter(dockerData, "NodejsDockerfile", WRENCH_RADISH_DOCKER_FILE), "Dockerfile")
-		if err != nil {
-			return errors.Wrap(err, "Error creating Dockerfile")
-		}
+	templateSet, err := templates.Load(dockerSpec.TemplateDir, baseImage.GetTemplateVersion())
+	if err != nil {
+		return err
+	}
+	if err := verifyTemplateVersion(templateSet, dockerData); err != nil {
+		return err
+	}
 
-	} else {
-		logrus.Info("Running nodejs legacy build...")
+	logrus.Infof("Running %s build", architecture.Name)
 
-		err = writer(util.NewTemplateWriter(nginxData, "NgnixConfiguration", NGINX_CONFIG_TEMPLATE), "nginx.conf")
-		if err != nil {
-			return errors.Wrap(err, "Error creating nginxData configuration")
-		}
-		err = writer(util.NewTemplateWriter(dockerData, "NodejsDockerfile", WRENCH_DOCKER_FILE), "Dockerfile")
-		if err != nil {
-			return errors.Wrap(err, "Error creating Dockerfile")
-		}
+	err = writer(architecture.Descriptor(dockerData, nginxData, templateSet), architecture.DescriptorFile)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to create %s", architecture.DescriptorFile)
+	}
+	dockerfileTemplate, err := templateSet.Get(architecture.DockerfileTemplate)
+	if err != nil {
+		return err
+	}
+	err = writer(util.NewTemplateWriter(dockerData, "NodejsDockerfile", dockerfileTemplate), "Dockerfile")
+	if err != nil {
+		return errors.Wrap(err, "Error creating Dockerfile")
 	}
 	err = addProbes(nginxData.HasNodeJSApplication, writer)
 	if err != nil {
@@ -133,6 +180,20 @@ func prepareImage(dockerSpec config.DockerSpec, v *openshiftJson, baseImage runt
 	return err
 }
 
+// The legacy templates copy one web application, and have no docker.extensions
+func verifyTemplateVersion(templateSet *templates.TemplateSet, docker *DockerfileData) error {
+	if !templateSet.Legacy() {
+		return nil
+	}
+	if len(docker.WebApps) > 0 {
+		return templates.LegacyError("More than one web application in openshift.json")
+	}
+	if docker.Extensions != "" {
+		return templates.LegacyError("docker.extensions in openshift.json")
+	}
+	return nil
+}
+
 func addProbes(hasNodejsApplication bool, writer util.FileWriter) error {
 	nginxProbe := &probe{
 		Include: true,
@@ -173,18 +234,25 @@ func findMaintainer(dockerMetadata dockerMetadata) string {
 }
 
 func mapOpenShiftJsonToTemplateInput(dockerSpec config.DockerSpec, v *openshiftJson, completeDockerName string, imageBuildTime string, auroraVersion *runtime.AuroraVersion) (*NginxfileData, *DockerfileData, error) {
-	labels := make(map[string]string)
+	// The labels in openshift.json have precedence over the OCI labels
+	labels := docker.OciLabels(v.BuildInfo, string(auroraVersion.GetAppVersion()), imageBuildTime)
 	if v.DockerMetadata.Labels != nil {
 		for k, v := range v.DockerMetadata.Labels {
 			labels[k] = v
 		}
 	}
+	extensions, err := docker.NewDockerExtensions(v.DockerMetadata.Extensions)
+	if err != nil {
+		return nil, nil, err
+	}
+	extensions.AddLabels(labels)
 	labels["version"] = string(auroraVersion.GetAppVersion())
 	labels["maintainer"] = findMaintainer(v.DockerMetadata)
 
+	webapps := webApplications(v.Aurora)
 	path := "/"
-	if v.Aurora.Webapp != nil && len(strings.TrimPrefix(v.Aurora.Webapp.Path, "/")) > 0 {
-		path = "/" + strings.TrimPrefix(v.Aurora.Webapp.Path, "/")
+	if len(webapps) > 0 && len(strings.TrimPrefix(webapps[0].Path, "/")) > 0 {
+		path = "/" + strings.TrimPrefix(webapps[0].Path, "/")
 	} else if len(strings.TrimPrefix(v.Aurora.Path, "/")) > 0 {
 		logrus.Warnf("web.path in openshift.json is deprecated. Please use web.webapp.path when setting path: %s", v.Aurora.Path)
 		path = "/" + strings.TrimPrefix(v.Aurora.Path, "/")
@@ -196,7 +264,6 @@ func mapOpenShiftJsonToTemplateInput(dockerSpec config.DockerSpec, v *openshiftJ
 
 	var nodejsMainfile string
 	var overrides map[string]string
-	var err error
 	if v.Aurora.NodeJS != nil {
 		nodejsMainfile = strings.TrimSpace(v.Aurora.NodeJS.Main)
 		overrides = v.Aurora.NodeJS.Overrides
@@ -205,25 +272,58 @@ func mapOpenShiftJsonToTemplateInput(dockerSpec config.DockerSpec, v *openshiftJ
 			return nil, nil, err
 		}
 	}
+	if err := validateProxies(v.Aurora.Proxies, webAppPaths(v.Aurora)); err != nil {
+		return nil, nil, err
+	}
+	if err := validateWebApps(v.Aurora); err != nil {
+		return nil, nil, err
+	}
 
 	var exclude []string
 	var static string
 	var spa bool
 	var extraHeaders map[string]string
+	var fingerprints string
+	var immutableHeaders map[string]string
+	var otherWebApps []webAppLocation
 	var gZip = nginxGzip{}
 	var nginxLocationMap = make(nginxLocations)
-	if v.Aurora.Webapp == nil {
+	if len(webapps) == 0 {
 		static = v.Aurora.Static
 		spa = v.Aurora.SPA
 		exclude = nil
 		extraHeaders = nil
 		nginxLocationMap = nil
 	} else {
-		static = v.Aurora.Webapp.StaticContent
-		spa = v.Aurora.Webapp.DisableTryfiles == false
-		extraHeaders = v.Aurora.Webapp.Headers
+		main, security, err := newWebAppLocation(webapps[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		static = main.Content
+		spa = main.SPA
+		extraHeaders = main.ExtraStaticHeaders
+		fingerprints = main.FingerprintPattern
+		immutableHeaders = main.ImmutableHeaders
+		for _, webapp := range webapps[1:] {
+			location, _, err := newWebAppLocation(webapp)
+			if err != nil {
+				return nil, nil, err
+			}
+			location.Precompress = v.Aurora.Precompress != nil
+			location.Brotli = v.ServeBrotli
+			location.StaticOverrides = overridesIn(staticContext, overrides)
+			otherWebApps = append(otherWebApps, location)
+		}
 		gZip = v.Aurora.Gzip
+		if v.Aurora.Precompress != nil && gZip.UseStatic == "" {
+			// Serve the precompressed files
+			gZip.UseStatic = "on"
+		}
 		nginxLocationMap = buildNginxLocations(v.Aurora.Locations)
+		locationSecurity := overriddenSecurityHeaders(security, webapps[0].Headers)
+		for _, location := range nginxLocationMap {
+			location.Headers = withSecurityHeaders(locationSecurity, location.Headers)
+		}
 
 		if v.Aurora.Exclude != nil {
 			for _, value := range v.Aurora.Exclude {
@@ -243,18 +343,31 @@ func mapOpenShiftJsonToTemplateInput(dockerSpec config.DockerSpec, v *openshiftJ
 	if auroraVersion.Snapshot {
 		env[docker.ENV_SNAPSHOT_TAG] = auroraVersion.GetGivenVersion()
 	}
+	if err := v.DockerMetadata.DeliverableEnv.AddTo(env); err != nil {
+		return nil, nil, err
+	}
+	addUpstreamDefaults(v.Aurora.Proxies, env)
 
 	return &NginxfileData{
 			HasNodeJSApplication: len(nodejsMainfile) != 0,
 			ConfigurableProxy:    v.Aurora.ConfigurableProxy,
-			NginxOverrides:       overrides,
+			NginxOverrides:       overridesIn(apiContext, overrides),
 			Path:                 path,
 			ExtraStaticHeaders:   extraHeaders,
+			FingerprintPattern:   fingerprints,
+			ImmutableHeaders:     immutableHeaders,
 			SPA:                  spa,
 			Content:              static,
 			Exclude:              exclude,
 			Gzip:                 gZip,
 			Locations:            nginxLocationMap,
+			Precompress:          v.Aurora.Precompress != nil,
+			Brotli:               v.ServeBrotli,
+			ServerOverrides:      overridesIn(serverContext, overrides),
+			StaticOverrides:      overridesIn(staticContext, overrides),
+			Proxies:              buildProxies(v.Aurora.Proxies, overridesIn(apiContext, overrides)),
+			ApiLocation:          !hasProxy(v.Aurora.Proxies, "/api"),
+			WebApps:              otherWebApps,
 		}, &DockerfileData{
 			Main:             nodejsMainfile,
 			Maintainer:       findMaintainer(v.DockerMetadata),
@@ -264,6 +377,8 @@ func mapOpenShiftJsonToTemplateInput(dockerSpec config.DockerSpec, v *openshiftJ
 			Labels:           labels,
 			Env:              env,
 			Path:             path,
+			Extensions:       extensions.Instructions(),
+			WebApps:          otherWebApps,
 		}, nil
 }
 
@@ -298,20 +413,15 @@ func buildNginxLocations(locations map[string]interface{}) nginxLocations {
 	return nginxLocationMap
 }
 
-func whitelistOverrides(overrides map[string]string) error {
-	if overrides == nil {
-		return nil
-	}
-
-	for key, value := range overrides {
-		validateFunc, exists := allowedNginxOverrides[key]
-		if !exists {
-			return errors.New("Config " + key + " is not allowed to override with Architect.")
-		}
-		var err error
-		if err = validateFunc(value); err != nil {
+// validateOpenshiftJson checks openshift.json as soon as it is read from the deliverable, before the image is prepared
+func validateOpenshiftJson(v *openshiftJson) error {
+	if v.Aurora.NodeJS != nil {
+		if err := whitelistOverrides(v.Aurora.NodeJS.Overrides); err != nil {
 			return err
 		}
 	}
-	return nil
+	if err := validateProxies(v.Aurora.Proxies, webAppPaths(v.Aurora)); err != nil {
+		return err
+	}
+	return validateWebApps(v.Aurora)
 }
@@ -42,3 +42,10 @@ func TestApplicationPrepare(t *testing.T) {
 	assert.Equal(t, "0.1.2", string(b.AuroraVersion.GetAppVersion()))
 	os.RemoveAll(b.BuildFolder)
 }
+
+func TestVersionReader(t *testing.T) {
+	deliverable := nexus.Deliverable{Path: "testfiles/openshift-referanse-react-snapshot_test-SNAPSHOT-Webleveransepakke.tgz"}
+	version, err := prepare.VersionReader()(&config.Config{}, deliverable)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.1.8", version)
+}
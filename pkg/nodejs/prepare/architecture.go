@@ -0,0 +1,59 @@
+package prepare
+
+import (
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/skatteetaten/architect/pkg/templates"
+	"github.com/skatteetaten/architect/pkg/util"
+	"io"
+)
+
+// The nginx configuration for one image architecture label, and the name of its Dockerfile template
+type imageArchitecture struct {
+	Name               string
+	DescriptorFile     string
+	Descriptor         func(docker *DockerfileData, nginx *NginxfileData, templateSet *templates.TemplateSet) util.WriterFunc
+	DockerfileTemplate string
+}
+
+var imageArchitectures = map[string]imageArchitecture{
+	"nodejs": {
+		Name:               "radish nodejs",
+		DescriptorFile:     "nginx-radish.json",
+		Descriptor:         newRadishNginxConfig,
+		DockerfileTemplate: templates.NodejsDockerfile,
+	},
+}
+
+// Base images without the image architecture label get the nginx.conf based build
+var legacyImageArchitecture = imageArchitecture{
+	Name:           "nodejs legacy",
+	DescriptorFile: "nginx.conf",
+	Descriptor: func(docker *DockerfileData, nginx *NginxfileData, templateSet *templates.TemplateSet) util.WriterFunc {
+		nginxTemplate, err := templateSet.Get(templates.NodejsLegacyNginxConf)
+		if err == nil {
+			err = verifyLegacyNginx(templateSet, nginx)
+		}
+		if err != nil {
+			return func(io.Writer) error {
+				return err
+			}
+		}
+		return util.NewTemplateWriter(nginx, "NgnixConfiguration", nginxTemplate)
+	},
+	DockerfileTemplate: templates.NodejsLegacyDockerfile,
+}
+
+func findImageArchitecture(baseImage runtime.BaseImage) (imageArchitecture, error) {
+	architecture := baseImage.GetImageArchitecture()
+	if architecture == "" {
+		return legacyImageArchitecture, nil
+	}
+	if handler, exists := imageArchitectures[architecture]; exists {
+		return handler, nil
+	}
+	supported := make([]string, 0, len(imageArchitectures))
+	for label := range imageArchitectures {
+		supported = append(supported, label)
+	}
+	return imageArchitecture{}, runtime.UnsupportedImageArchitectureError(architecture, supported)
+}
@@ -0,0 +1,86 @@
+package prepare
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/docker"
+	"github.com/skatteetaten/architect/pkg/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	packageJsonInTarball   = "package/package.json"
+	gitPropertiesInTarball = "package/metadata/git.properties"
+)
+
+// The version of the package, and the fields npm adds to package.json when a package is published from a git repository
+type packageJson struct {
+	Version    string          `json:"version"`
+	GitHead    string          `json:"gitHead"`
+	Repository json.RawMessage `json:"repository"`
+}
+
+/*
+readBuildInfo reads the git metadata in the extracted package. The commit and the repository are taken from
+package.json, and a git.properties next to openshift.json has precedence since it also has the branch and the
+build time. It returns nil when the package has neither.
+*/
+func readBuildInfo(pathToApplication string) (*docker.BuildInfo, error) {
+	var buildInfo *docker.BuildInfo
+
+	content, err := ioutil.ReadFile(filepath.Join(pathToApplication, packageJsonInTarball))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "Error reading package.json")
+	} else if err == nil {
+		var p packageJson
+		if err := json.Unmarshal(content, &p); err != nil {
+			return nil, errors.Wrap(err, "Error reading package.json")
+		}
+		if source := p.repositoryUrl(); p.GitHead != "" || source != "" {
+			buildInfo = &docker.BuildInfo{Revision: p.GitHead, Source: source}
+		}
+	}
+
+	gitProperties, err := os.Open(filepath.Join(pathToApplication, gitPropertiesInTarball))
+	if os.IsNotExist(err) {
+		return buildInfo, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Error reading git.properties")
+	}
+	defer gitProperties.Close()
+
+	properties, err := util.ReadProperties(gitProperties)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading git.properties")
+	}
+	fromProperties := docker.NewBuildInfoFromGitProperties(properties)
+	if buildInfo != nil {
+		if fromProperties.Revision == "" {
+			fromProperties.Revision = buildInfo.Revision
+		}
+		if fromProperties.Source == "" {
+			fromProperties.Source = buildInfo.Source
+		}
+	}
+	return fromProperties, nil
+}
+
+// repository is either an url or an object with type and url
+func (p packageJson) repositoryUrl() string {
+	if len(p.Repository) == 0 {
+		return ""
+	}
+	var url string
+	if err := json.Unmarshal(p.Repository, &url); err == nil {
+		return url
+	}
+	var repository struct {
+		Url string `json:"url"`
+	}
+	if err := json.Unmarshal(p.Repository, &repository); err == nil {
+		return repository.Url
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package prepare
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadBuildInfoFromPackageJson(t *testing.T) {
+	pathToApplication := writeTestPackage(t, map[string]string{
+		packageJsonInTarball: `{"name": "wrench", "version": "1.2.3", "gitHead": "3f5c9a1e2b7d4c6f8a0e1d2c3b4a5f6e7d8c9b0a",
+			"repository": {"type": "git", "url": "https://git.aurora.skead.no/scm/aurora/wrench.git"}}`,
+	})
+	defer os.RemoveAll(pathToApplication)
+
+	buildInfo, err := readBuildInfo(pathToApplication)
+	assert.NoError(t, err)
+	assert.Equal(t, "3f5c9a1e2b7d4c6f8a0e1d2c3b4a5f6e7d8c9b0a", buildInfo.Revision)
+	assert.Equal(t, "https://git.aurora.skead.no/scm/aurora/wrench.git", buildInfo.Source)
+}
+
+func TestReadBuildInfoFromGitProperties(t *testing.T) {
+	pathToApplication := writeTestPackage(t, map[string]string{
+		packageJsonInTarball:   `{"name": "wrench", "repository": "https://git.aurora.skead.no/scm/aurora/wrench.git"}`,
+		gitPropertiesInTarball: "git.branch=master\ngit.build.time=2018-09-10T14\\:30\\:10+0200\ngit.commit.id=3f5c9a1\n",
+	})
+	defer os.RemoveAll(pathToApplication)
+
+	buildInfo, err := readBuildInfo(pathToApplication)
+	assert.NoError(t, err)
+	assert.Equal(t, "3f5c9a1", buildInfo.Revision)
+	assert.Equal(t, "master", buildInfo.Branch)
+	assert.Equal(t, "2018-09-10T14:30:10+0200", buildInfo.BuildTime)
+	assert.Equal(t, "https://git.aurora.skead.no/scm/aurora/wrench.git", buildInfo.Source)
+}
+
+func TestReadBuildInfoWithoutGitMetadata(t *testing.T) {
+	pathToApplication := writeTestPackage(t, map[string]string{
+		packageJsonInTarball: `{"name": "wrench", "version": "1.2.3"}`,
+	})
+	defer os.RemoveAll(pathToApplication)
+
+	buildInfo, err := readBuildInfo(pathToApplication)
+	assert.NoError(t, err)
+	assert.Nil(t, buildInfo)
+}
+
+func writeTestPackage(t *testing.T, files map[string]string) string {
+	pathToApplication, err := ioutil.TempDir("", "nodejs-buildinfo")
+	assert.NoError(t, err)
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(pathToApplication, name)), 0755))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(pathToApplication, name), []byte(content), 0644))
+	}
+	return pathToApplication
+}
@@ -0,0 +1,87 @@
+package prepare
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+// The values of webapp.cacheStrategy in openshift.json
+const (
+	CacheStrategyNone          = "none"
+	CacheStrategyFingerprinted = "fingerprinted"
+)
+
+// Matches hashed file names like main.3f9a1c.js and index-4ed993c7.js
+const DefaultFingerprintPattern = `[.-][0-9a-f]{6,}\.[a-z0-9]+$`
+
+const (
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	defaultCacheControl   = "no-cache"
+)
+
+// The pattern is written in quotes in nginx.conf
+var illegalPatternCharacters = regexp.MustCompile(`["\s;]`)
+
+/*
+cacheHeaders returns the headers of the static content with the fingerprinted cache strategy, and the headers of the
+files matching the fingerprint pattern. A fingerprinted file changes name when its content changes, so it is cached for
+a year. Every other file, like index.html and the SPA fallback, is revalidated with no-cache, unless webapp.headers
+sets Cache-Control.
+*/
+func cacheHeaders(headers map[string]string) (map[string]string, map[string]string) {
+	staticHeaders := make(map[string]string)
+	immutableHeaders := make(map[string]string)
+	hasCacheControl := false
+	for key, value := range headers {
+		staticHeaders[key] = value
+		if strings.EqualFold(key, "Cache-Control") {
+			hasCacheControl = true
+			continue
+		}
+		immutableHeaders[key] = value
+	}
+	if !hasCacheControl {
+		staticHeaders["Cache-Control"] = defaultCacheControl
+	}
+	immutableHeaders["Cache-Control"] = immutableCacheControl
+	return staticHeaders, immutableHeaders
+}
+
+// fingerprintPattern returns the pattern of the fingerprinted files, or an empty string without the fingerprinted
+// cache strategy
+func fingerprintPattern(webapp *webApplication) string {
+	if webapp == nil || webapp.CacheStrategy != CacheStrategyFingerprinted {
+		return ""
+	}
+	if webapp.FingerprintPattern != "" {
+		return webapp.FingerprintPattern
+	}
+	return DefaultFingerprintPattern
+}
+
+func validateCacheStrategy(webapp *webApplication) error {
+	if webapp == nil {
+		return nil
+	}
+	switch webapp.CacheStrategy {
+	case "", CacheStrategyNone:
+		if webapp.FingerprintPattern != "" {
+			return errors.Errorf("webapp.fingerprintPattern needs webapp.cacheStrategy %s", CacheStrategyFingerprinted)
+		}
+	case CacheStrategyFingerprinted:
+		if webapp.FingerprintPattern == "" {
+			return nil
+		}
+		if illegalPatternCharacters.MatchString(webapp.FingerprintPattern) {
+			return errors.Errorf("Illegal webapp.fingerprintPattern %s. Quotes, semicolons and whitespace are not allowed", webapp.FingerprintPattern)
+		}
+		if _, err := regexp.Compile(webapp.FingerprintPattern); err != nil {
+			return errors.Wrapf(err, "Illegal webapp.fingerprintPattern %s", webapp.FingerprintPattern)
+		}
+	default:
+		return errors.Errorf("Illegal webapp.cacheStrategy %s. Use %s or %s", webapp.CacheStrategy,
+			CacheStrategyNone, CacheStrategyFingerprinted)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package prepare
+
+import (
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestThatFingerprintedFilesAreImmutableLegacy(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.Webapp = &webApplication{
+		StaticContent: "app",
+		Headers:       map[string]string{"X-Frame-Options": "DENY"},
+		CacheStrategy: CacheStrategyFingerprinted,
+	}
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{runtime.TemplateVersionLabel: "2"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	assert.Contains(t, files["nginx.conf"], `
+       location / {
+          root /u01/static;
+          try_files $uri /index.html;
+          add_header Cache-Control "no-cache";
+          add_header X-Frame-Options "DENY";
+
+          location ~ "[.-][0-9a-f]{6,}\.[a-z0-9]+$" {
+             add_header Cache-Control "public, max-age=31536000, immutable";
+             add_header X-Frame-Options "DENY";
+          }
+       }`)
+}
+
+func TestThatFingerprintedFilesAreImmutableRadish(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.Webapp = &webApplication{
+		StaticContent:      "app",
+		Headers:            map[string]string{"cache-control": "max-age=60"},
+		CacheStrategy:      CacheStrategyFingerprinted,
+		FingerprintPattern: `\.[0-9a-f]{20}\.js$`,
+	}
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{"www.skatteetaten.no-imageArchitecture": "nodejs"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	data, err := UnmarshallOpenshiftConfig(strings.NewReader(files["nginx-radish.json"]))
+	assert.NoError(t, err)
+	assert.Equal(t, `\.[0-9a-f]{20}\.js$`, data.Web.WebApp.FingerprintPattern)
+	assert.Equal(t, map[string]string{"cache-control": "max-age=60"}, data.Web.WebApp.Headers)
+	assert.Equal(t, map[string]string{"Cache-Control": "public, max-age=31536000, immutable"}, data.Web.WebApp.ImmutableHeaders)
+}
+
+func TestThatNoCacheStrategyLeavesHeadersAlone(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.Webapp = &webApplication{StaticContent: "app"}
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{"www.skatteetaten.no-imageArchitecture": "nodejs"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, files["nginx-radish.json"], "fingerprintPattern")
+	assert.NotContains(t, files["nginx-radish.json"], "immutableHeaders")
+}
+
+func TestValidateCacheStrategy(t *testing.T) {
+	assert.NoError(t, validateCacheStrategy(nil))
+	assert.NoError(t, validateCacheStrategy(&webApplication{CacheStrategy: CacheStrategyNone}))
+	assert.NoError(t, validateCacheStrategy(&webApplication{CacheStrategy: CacheStrategyFingerprinted}))
+	assert.Error(t, validateCacheStrategy(&webApplication{CacheStrategy: "forever"}))
+	assert.Error(t, validateCacheStrategy(&webApplication{FingerprintPattern: `\.js$`}))
+	assert.Error(t, validateCacheStrategy(&webApplication{CacheStrategy: CacheStrategyFingerprinted, FingerprintPattern: `\.js" {`}))
+	assert.Error(t, validateCacheStrategy(&webApplication{CacheStrategy: CacheStrategyFingerprinted, FingerprintPattern: `[`}))
+}
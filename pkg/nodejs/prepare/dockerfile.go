@@ -9,52 +9,11 @@ type DockerfileData struct {
 	Path             string
 	Labels           map[string]string
 	Env              map[string]string
+	Extensions       string
+	// The web applications after the first one
+	WebApps []webAppLocation
 }
 
-const WRENCH_DOCKER_FILE string = `FROM {{.Baseimage}}
-
-LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
-
-COPY ./{{.PackageDirectory}} /u01/application
-
-COPY ./overrides /u01/bin/
-
-COPY ./{{.PackageDirectory}}/{{.Static}} /u01/static{{.Path}}
-
-COPY nginx.conf /etc/nginx/nginx.conf
-
-RUN chmod 666 /etc/nginx/nginx.conf && \
-    chmod 777 /etc/nginx && \
-    chmod 755 /u01/bin/*
-
-ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
-
-WORKDIR "/u01/"
-
-CMD ["/u01/bin/run_nginx"]`
-
-const WRENCH_RADISH_DOCKER_FILE string = `FROM {{.Baseimage}}
-
-LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
-
-COPY ./{{.PackageDirectory}} /u01/application
-
-COPY ./overrides /u01/bin/
-
-COPY nginx-radish.json $HOME/
-
-COPY ./{{.PackageDirectory}}/{{.Static}} /u01/static{{.Path}}
-
-RUN chmod 666 /etc/nginx/nginx.conf && \
-    chmod 777 /etc/nginx && \
-    chmod 755 /u01/bin/*
-
-ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
-
-WORKDIR "/u01/"
-
-CMD ["/u01/bin/run_nginx"]`
-
 //We copy this over the script in wrench if we don't have a nodejs app
 const BLOCKING_RUN_NODEJS string = `#!/bin/sh
 echo "Use of node.js was not configured in openshift.json. Blocking run script."
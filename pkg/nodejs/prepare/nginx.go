@@ -1,5 +1,9 @@
 package prepare
 
+import (
+	"github.com/skatteetaten/architect/pkg/templates"
+)
+
 type NginxfileData struct {
 	HasNodeJSApplication bool
 	ConfigurableProxy    bool
@@ -11,51 +15,39 @@ type NginxfileData struct {
 	Exclude              []string
 	Gzip                 nginxGzip
 	Locations            nginxLocations
+	// The static content has .gz files, and .br files if Brotli is set
+	Precompress bool
+	Brotli      bool
+	// The files matching FingerprintPattern get ImmutableHeaders. Empty without the fingerprinted cache strategy
+	FingerprintPattern string
+	ImmutableHeaders   map[string]string
+	// The overrides written in the server block and the static location. NginxOverrides go in the /api location
+	ServerOverrides map[string]string
+	StaticOverrides map[string]string
+	// Written before the /api location. Radish replaces the /api location with them
+	Proxies []nginxProxy
+	// The /api location is written unless a proxy has the path /api
+	ApiLocation bool
+	// The web applications after the first one
+	WebApps []webAppLocation
 }
 
-const NGINX_CONFIG_TEMPLATE string = `
-worker_processes  1;
-error_log stderr;
-
-events {
-    worker_connections  1024;
-}
-
-
-http {
-    include       /etc/nginx/mime.types;
-    default_type  application/octet-stream;
-
-    log_format  main  '$remote_addr - $remote_user [$time_local] "$request" '
-                      '$status $body_bytes_sent "$http_referer" '
-                      '"$http_user_agent" "$http_x_forwarded_for"';
-
-    access_log  /dev/stdout;
-
-    sendfile        on;
-    #tcp_nopush     on;
-
-    keepalive_timeout  65;
-
-    #gzip  on;
-
-    index index.html;
-
-    server {
-       listen 8080;
-
-       location /api {
-          {{if or .HasNodeJSApplication .ConfigurableProxy}}proxy_pass http://${PROXY_PASS_HOST}:${PROXY_PASS_PORT};{{else}}return 404;{{end}}{{range $key, $value := .NginxOverrides}}
-          {{$key}} {{$value}};{{end}}
-       }
-{{if .SPA}}
-       location {{.Path}} {
-          root /u01/static;
-          try_files $uri {{.Path}}index.html;{{else}}
-       location {{.Path}} {
-          root /u01/static;{{end}}{{range $key, $value := .ExtraStaticHeaders}}
-          add_header {{$key}} "{{$value}}";{{end}}
-       }
-    }
+// The legacy nginx.conf only has the /api location and the static location of the web application
+func verifyLegacyNginx(templateSet *templates.TemplateSet, nginx *NginxfileData) error {
+	if !templateSet.Legacy() {
+		return nil
+	}
+	if len(nginx.Proxies) > 0 {
+		return templates.LegacyError("proxies in openshift.json")
+	}
+	if len(nginx.ServerOverrides) > 0 || len(nginx.StaticOverrides) > 0 {
+		return templates.LegacyError("nginx overrides outside the /api location")
+	}
+	if nginx.Precompress {
+		return templates.LegacyError("precompress in openshift.json")
+	}
+	if nginx.FingerprintPattern != "" {
+		return templates.LegacyError("The fingerprinted cache strategy")
+	}
+	return nil
 }
-`
@@ -163,7 +163,9 @@ func TestThatOverridesAreWhitelistedAndSetCorrectly(t *testing.T) {
 		"a_value_not_whitelisted": "value",
 	}
 	_, _, err := mapObject(&openshiftJson)
-	assert.EqualError(t, err, "Config a_value_not_whitelisted is not allowed to override with Architect.")
+	assert.EqualError(t, err, "Config a_value_not_whitelisted is not allowed to override with Architect. Allowed are "+
+		"client_body_timeout (1s-120s), client_max_body_size (1m-50m), etag (on|off), keepalive_requests (1-10000), "+
+		"keepalive_timeout (0s-120s), proxy_buffer_size (4k-64k), proxy_read_timeout (1s-300s), proxy_send_timeout (1s-300s)")
 
 	openshiftJson.Aurora.NodeJS.Overrides = map[string]string{
 		"client_max_body_size": "51m",
@@ -290,6 +292,36 @@ func TestThatNoCustomLocationsIsPresentInNginx(t *testing.T) {
 	assert.Equal(t, 0, len(nginxfileData.Locations))
 }
 
+func TestThatDockerEnvIsAddedAndArchitectEnvIsProtected(t *testing.T) {
+	openshiftJson := openshiftJson{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"docker": {"maintainer": "me", "timezone": "UTC", "locale": "nb_NO.UTF-8",
+		"env": {"FEATURE_TOGGLE": "on"}}}`), &openshiftJson))
+	_, dockerfileData, err := mapObject(&openshiftJson)
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", dockerfileData.Env["TZ"])
+	assert.Equal(t, "nb_NO.UTF-8", dockerfileData.Env["LANG"])
+	assert.Equal(t, "on", dockerfileData.Env["FEATURE_TOGGLE"])
+
+	openshiftJson.DockerMetadata.Env = map[string]string{"PROXY_PASS_PORT": "8000"}
+	_, _, err = mapObject(&openshiftJson)
+	assert.EqualError(t, err, "PROXY_PASS_PORT is set by Architect and cannot be overridden in docker.env")
+}
+
+func TestThatDockerExtensionsAreAdded(t *testing.T) {
+	openshiftJson := openshiftJson{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"docker": {"maintainer": "me",
+		"extensions": {"volumes": ["/u01/cache"], "labels": {"maintainer": "ignored", "team": "aurora"}}}}`), &openshiftJson))
+	_, dockerfileData, err := mapObject(&openshiftJson)
+	assert.NoError(t, err)
+	assert.Equal(t, `VOLUME ["/u01/cache"]`, dockerfileData.Extensions)
+	assert.Equal(t, "me", dockerfileData.Labels["maintainer"])
+	assert.Equal(t, "aurora", dockerfileData.Labels["team"])
+
+	openshiftJson.DockerMetadata.Extensions = map[string]json.RawMessage{"entrypoint": json.RawMessage(`"sh"`)}
+	_, _, err = mapObject(&openshiftJson)
+	assert.EqualError(t, err, "Extension entrypoint is not allowed in docker.extensions")
+}
+
 func mapObject(openshiftJson *openshiftJson) (*NginxfileData, *DockerfileData, error) {
 	dockerSpec := config.DockerSpec{
 		PushExtraTags: config.ParseExtraTags("major"),
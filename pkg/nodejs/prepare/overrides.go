@@ -0,0 +1,141 @@
+package prepare
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The kinds of values an nginx override can have
+const (
+	overrideSize     = "size"
+	overrideDuration = "duration"
+	overrideInt      = "int"
+	overrideEnum     = "enum"
+)
+
+// Where in nginx.conf an override is written
+const (
+	apiContext    = "api"
+	serverContext = "server"
+	staticContext = "static"
+)
+
+// nginxOverride describes the values allowed for an nginx directive in nodejs.overrides
+type nginxOverride struct {
+	Type string
+	// The unit of a size or a duration, like m, k or s
+	Unit string
+	// The bounds of size, duration and int values
+	Min int
+	Max int
+	// The values of an enum
+	Values  []string
+	Context string
+}
+
+/*
+We sanitize the input.... Don't want to large inputs.
+
+For example; Accepting very large client_max_body_size would make a DOS attack very easy to implement...
+*/
+var allowedNginxOverrides = map[string]nginxOverride{
+	"client_max_body_size": {Type: overrideSize, Unit: "m", Min: 1, Max: 50, Context: apiContext},
+	"proxy_read_timeout":   {Type: overrideDuration, Unit: "s", Min: 1, Max: 300, Context: apiContext},
+	"proxy_send_timeout":   {Type: overrideDuration, Unit: "s", Min: 1, Max: 300, Context: apiContext},
+	"proxy_buffer_size":    {Type: overrideSize, Unit: "k", Min: 4, Max: 64, Context: apiContext},
+	"client_body_timeout":  {Type: overrideDuration, Unit: "s", Min: 1, Max: 120, Context: serverContext},
+	"keepalive_timeout":    {Type: overrideDuration, Unit: "s", Min: 0, Max: 120, Context: serverContext},
+	"keepalive_requests":   {Type: overrideInt, Min: 1, Max: 10000, Context: serverContext},
+	"etag":                 {Type: overrideEnum, Values: []string{"on", "off"}, Context: staticContext},
+}
+
+// No leading zeros, and no signs
+var overrideNumber = regexp.MustCompile("^(0|[1-9][0-9]*)$")
+
+func (o nginxOverride) validate(key string, value string) error {
+	switch o.Type {
+	case overrideSize, overrideDuration:
+		if !strings.HasSuffix(value, o.Unit) || !o.inBounds(strings.TrimSuffix(value, o.Unit)) {
+			return errors.Errorf("Value on %s should be on the form N%s where N is between %d and %d", key, o.Unit, o.Min, o.Max)
+		}
+	case overrideInt:
+		if !o.inBounds(value) {
+			return errors.Errorf("Value on %s should be a number between %d and %d", key, o.Min, o.Max)
+		}
+	case overrideEnum:
+		for _, allowed := range o.Values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return errors.Errorf("Value on %s should be one of %s", key, strings.Join(o.Values, ", "))
+	default:
+		return errors.Errorf("Unknown type %s of %s", o.Type, key)
+	}
+	return nil
+}
+
+func (o nginxOverride) inBounds(number string) bool {
+	if !overrideNumber.MatchString(number) {
+		return false
+	}
+	n, err := strconv.Atoi(number)
+	return err == nil && n >= o.Min && n <= o.Max
+}
+
+func (o nginxOverride) String() string {
+	switch o.Type {
+	case overrideSize, overrideDuration:
+		return fmt.Sprintf("%d%s-%d%s", o.Min, o.Unit, o.Max, o.Unit)
+	case overrideInt:
+		return fmt.Sprintf("%d-%d", o.Min, o.Max)
+	default:
+		return strings.Join(o.Values, "|")
+	}
+}
+
+// allowedOverrides lists the overrides and their values for the error messages
+func allowedOverrides() string {
+	var allowed []string
+	for key, override := range allowedNginxOverrides {
+		allowed = append(allowed, key+" ("+override.String()+")")
+	}
+	sort.Strings(allowed)
+	return strings.Join(allowed, ", ")
+}
+
+func whitelistOverrides(overrides map[string]string) error {
+	if overrides == nil {
+		return nil
+	}
+
+	for key, value := range overrides {
+		override, exists := allowedNginxOverrides[key]
+		if !exists {
+			return errors.New("Config " + key + " is not allowed to override with Architect. Allowed are " + allowedOverrides())
+		}
+		if err := override.validate(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overridesIn returns the overrides written in the given context of nginx.conf
+func overridesIn(context string, overrides map[string]string) map[string]string {
+	var found map[string]string
+	for key, value := range overrides {
+		if allowedNginxOverrides[key].Context != context {
+			continue
+		}
+		if found == nil {
+			found = make(map[string]string)
+		}
+		found[key] = value
+	}
+	return found
+}
@@ -0,0 +1,104 @@
+package prepare
+
+import (
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestThatOverrideValuesAreValidated(t *testing.T) {
+	valid := map[string][]string{
+		"client_max_body_size": {"1m", "50m"},
+		"proxy_read_timeout":   {"1s", "300s"},
+		"proxy_buffer_size":    {"4k", "64k"},
+		"keepalive_timeout":    {"0s", "120s"},
+		"keepalive_requests":   {"1", "10000"},
+		"etag":                 {"on", "off"},
+	}
+	for key, values := range valid {
+		for _, value := range values {
+			assert.NoError(t, whitelistOverrides(map[string]string{key: value}), key+" "+value)
+		}
+	}
+
+	invalid := map[string][]string{
+		"client_max_body_size": {"0m", "51m", "5", "5k", "05m", "-1m", "5m;"},
+		"proxy_read_timeout":   {"301s", "1m", "s"},
+		"proxy_buffer_size":    {"2k", "1m"},
+		"keepalive_requests":   {"0", "1e3", "10001"},
+		"etag":                 {"true", "on;"},
+	}
+	for key, values := range invalid {
+		for _, value := range values {
+			assert.Error(t, whitelistOverrides(map[string]string{key: value}), key+" "+value)
+		}
+	}
+}
+
+func TestThatOverrideErrorsSayWhatIsAllowed(t *testing.T) {
+	assert.EqualError(t, whitelistOverrides(map[string]string{"proxy_read_timeout": "1h"}),
+		"Value on proxy_read_timeout should be on the form Ns where N is between 1 and 300")
+	assert.EqualError(t, whitelistOverrides(map[string]string{"keepalive_requests": "many"}),
+		"Value on keepalive_requests should be a number between 1 and 10000")
+	assert.EqualError(t, whitelistOverrides(map[string]string{"etag": "yes"}),
+		"Value on etag should be one of on, off")
+}
+
+var contextOverrides = map[string]string{
+	"client_max_body_size": "5m",
+	"proxy_read_timeout":   "120s",
+	"keepalive_timeout":    "30s",
+	"etag":                 "off",
+}
+
+func TestThatOverridesAreWrittenInTheirContextLegacy(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.NodeJS = &nodeJSApplication{Main: "test.js", Overrides: contextOverrides}
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{runtime.TemplateVersionLabel: "2"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	assert.Contains(t, files["nginx.conf"], `
+    server {
+       listen 8080;
+       keepalive_timeout 30s;
+
+       location /api {
+          proxy_pass http://${PROXY_PASS_HOST}:${PROXY_PASS_PORT};
+          client_max_body_size 5m;
+          proxy_read_timeout 120s;
+       }
+
+       location / {
+          root /u01/static;
+          try_files $uri /index.html;
+          etag off;`)
+}
+
+func TestThatOverridesAreWrittenInTheirContextRadish(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.NodeJS = &nodeJSApplication{Main: "test.js", Overrides: contextOverrides}
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{"www.skatteetaten.no-imageArchitecture": "nodejs"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	data, err := UnmarshallOpenshiftConfig(strings.NewReader(files["nginx-radish.json"]))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"client_max_body_size": "5m", "proxy_read_timeout": "120s"}, data.Web.Nodejs.Overrides)
+	assert.Equal(t, map[string]string{"keepalive_timeout": "30s"}, data.Web.Overrides)
+	assert.Equal(t, map[string]string{"etag": "off"}, data.Web.WebApp.Overrides)
+}
@@ -0,0 +1,182 @@
+package prepare
+
+import (
+	"compress/gzip"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The smallest file that is compressed when web.precompress has no minSize
+const DefaultPrecompressMinSize = 1024
+
+// The extensions of the text based files worth compressing. Images, fonts like woff2 and archives are compressed already
+var compressibleExtensions = map[string]bool{
+	".html": true, ".htm": true, ".css": true, ".js": true, ".mjs": true, ".json": true, ".map": true,
+	".svg": true, ".xml": true, ".txt": true, ".wasm": true, ".ico": true, ".ttf": true, ".otf": true,
+	".eot": true, ".webmanifest": true,
+}
+
+// precompress is web.precompress in openshift.json
+type precompress struct {
+	MinSize int64 `json:"minSize"` // Optional. Defaults to DefaultPrecompressMinSize bytes
+	Brotli  bool  `json:"brotli"`  // Optional. Writes .br files as well, when the brotli command is available
+}
+
+// precompressWebApps precompresses the static content of every web application. It returns true if .br files were written
+func precompressWebApps(packageFolder string, a auroraApplication) (bool, error) {
+	brotliWritten := false
+	for i, content := range staticContents(a) {
+		// The locations are paths in the main web application. nginx only writes them with web.webapp
+		var locations nginxLocations
+		if i == 0 && len(webApplications(a)) > 0 {
+			locations = buildNginxLocations(a.Locations)
+		}
+		written, err := precompressStaticContent(filepath.Join(packageFolder, content), a.Precompress, a.Gzip, locations)
+		if err != nil {
+			return false, err
+		}
+		brotliWritten = brotliWritten || written
+	}
+	return brotliWritten, nil
+}
+
+/*
+precompressStaticContent writes .gz, and optionally .br, siblings of the compressible files in the static content, so
+nginx can serve them with gzip_static and brotli_static. A file is not compressed when the most specific location
+containing it has gzip.use_static set to off, or web.gzip.use_static is off and no location turns it on.
+
+Files that already have the compressed sibling are left alone, and compressed files that are not smaller than the
+original are removed. It returns true if any .br file was written, so brotli_static is only turned on when there
+are files to serve.
+*/
+func precompressStaticContent(contentFolder string, settings *precompress, gzipSettings nginxGzip, locations nginxLocations) (bool, error) {
+	if settings == nil {
+		return false, nil
+	}
+	minSize := settings.MinSize
+	if minSize == 0 {
+		minSize = DefaultPrecompressMinSize
+	}
+	brotli := ""
+	if settings.Brotli {
+		var err error
+		if brotli, err = exec.LookPath("brotli"); err != nil {
+			logrus.Warn("web.precompress.brotli is set, but the brotli command is not available. Only gzip files are written")
+		}
+	}
+
+	count := 0
+	brotliWritten := false
+	err := filepath.Walk(contentFolder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || info.Size() < minSize || !compressibleExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		relativePath, err := filepath.Rel(contentFolder, path)
+		if err != nil {
+			return err
+		}
+		if findUseStatic(filepath.ToSlash(relativePath), gzipSettings, locations) == "off" {
+			return nil
+		}
+		if _, err := writeCompressed(path, ".gz", info.Size(), gzipFile); err != nil {
+			return errors.Wrapf(err, "Failed to gzip %s", relativePath)
+		}
+		if brotli != "" {
+			written, err := writeCompressed(path, ".br", info.Size(), brotliFile(brotli))
+			if err != nil {
+				return errors.Wrapf(err, "Failed to compress %s with brotli", relativePath)
+			}
+			brotliWritten = brotliWritten || written
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	logrus.Infof("Precompressed %d static files", count)
+	return brotliWritten, nil
+}
+
+// findUseStatic returns gzip.use_static of the longest location matching the path, or web.gzip.use_static
+func findUseStatic(relativePath string, gzipSettings nginxGzip, locations nginxLocations) string {
+	keys := make([]string, 0, len(locations))
+	for key := range locations {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return len(keys[i]) > len(keys[j])
+	})
+	for _, key := range keys {
+		location := strings.Trim(key, "/")
+		if relativePath != location && !strings.HasPrefix(relativePath, location+"/") {
+			continue
+		}
+		if useStatic := locations[key].Gzip.UseStatic; useStatic != "" {
+			return useStatic
+		}
+		break
+	}
+	return gzipSettings.UseStatic
+}
+
+// writeCompressed returns true if the compressed file is there afterwards, either from the deliverable or written now
+func writeCompressed(path string, extension string, size int64, compress func(source string, target string) error) (bool, error) {
+	target := path + extension
+	if _, err := os.Stat(target); err == nil {
+		return true, nil
+	}
+	if err := compress(path, target); err != nil {
+		os.Remove(target)
+		return false, err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() >= size {
+		return false, os.Remove(target)
+	}
+	return true, nil
+}
+
+func gzipFile(source string, target string) error {
+	sourceFile, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+	targetFile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer targetFile.Close()
+
+	gzipWriter, err := gzip.NewWriterLevel(targetFile, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(gzipWriter, sourceFile); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func brotliFile(command string) func(source string, target string) error {
+	return func(source string, target string) error {
+		output, err := exec.Command(command, "--best", "--keep", "--output="+target, source).CombinedOutput()
+		if err != nil {
+			return errors.Wrapf(err, "brotli failed: %s", strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+}
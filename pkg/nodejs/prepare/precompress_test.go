@@ -0,0 +1,177 @@
+package prepare
+
+import (
+	"compress/gzip"
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrecompressStaticContent(t *testing.T) {
+	content, err := ioutil.TempDir("", "precompress")
+	assert.NoError(t, err)
+	defer os.RemoveAll(content)
+
+	script := strings.Repeat("console.log('hello');\n", 100)
+	writeTestFile(t, content, "main.js", script)
+	writeTestFile(t, content, "small.css", "body {}")
+	writeTestFile(t, content, "logo.png", strings.Repeat("x", 2000))
+	writeTestFile(t, content, "vendor/lib.js", script)
+	writeTestFile(t, content, "vendor/gzipped/lib.js", script)
+
+	locations := nginxLocations{
+		"vendor":         &nginxLocation{Gzip: nginxGzip{UseStatic: "off"}},
+		"vendor/gzipped": &nginxLocation{Gzip: nginxGzip{UseStatic: "on"}},
+	}
+	brotliWritten, err := precompressStaticContent(content, &precompress{}, nginxGzip{}, locations)
+	assert.NoError(t, err)
+	assert.False(t, brotliWritten)
+
+	assert.FileExists(t, filepath.Join(content, "main.js.gz"))
+	assert.FileExists(t, filepath.Join(content, "vendor", "gzipped", "lib.js.gz"))
+	assertNoFile(t, filepath.Join(content, "small.css.gz"))
+	assertNoFile(t, filepath.Join(content, "logo.png.gz"))
+	assertNoFile(t, filepath.Join(content, "vendor", "lib.js.gz"))
+
+	gzipped, err := os.Open(filepath.Join(content, "main.js.gz"))
+	assert.NoError(t, err)
+	defer gzipped.Close()
+	reader, err := gzip.NewReader(gzipped)
+	assert.NoError(t, err)
+	unzipped, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, script, string(unzipped))
+}
+
+func TestPrecompressIsOffWithoutSettings(t *testing.T) {
+	content, err := ioutil.TempDir("", "precompress")
+	assert.NoError(t, err)
+	defer os.RemoveAll(content)
+	writeTestFile(t, content, "main.js", strings.Repeat("console.log('hello');\n", 100))
+
+	brotliWritten, err := precompressStaticContent(content, nil, nginxGzip{UseStatic: "on"}, nil)
+	assert.NoError(t, err)
+	assert.False(t, brotliWritten)
+	assertNoFile(t, filepath.Join(content, "main.js.gz"))
+}
+
+func TestThatLocationsOnlyApplyToTheMainWebApp(t *testing.T) {
+	packageFolder, err := ioutil.TempDir("", "precompress")
+	assert.NoError(t, err)
+	defer os.RemoveAll(packageFolder)
+
+	script := strings.Repeat("console.log('hello');\n", 100)
+	writeTestFile(t, packageFolder, "app/vendor/lib.js", script)
+	writeTestFile(t, packageFolder, "docs/vendor/lib.js", script)
+
+	a := auroraApplication{
+		Webapp:      &webApplication{StaticContent: "app"},
+		Webapps:     []webApplication{{StaticContent: "docs", Path: "/docs/"}},
+		Precompress: &precompress{},
+		Locations: map[string]interface{}{
+			"vendor": map[string]interface{}{"gzip": map[string]interface{}{"use_static": "off"}},
+		},
+	}
+	brotliWritten, err := precompressWebApps(packageFolder, a)
+	assert.NoError(t, err)
+	assert.False(t, brotliWritten)
+
+	assertNoFile(t, filepath.Join(packageFolder, "app", "vendor", "lib.js.gz"))
+	assert.FileExists(t, filepath.Join(packageFolder, "docs", "vendor", "lib.js.gz"))
+}
+
+func TestFindUseStatic(t *testing.T) {
+	locations := nginxLocations{
+		"index.html": &nginxLocation{Gzip: nginxGzip{UseStatic: "off"}},
+		"assets/":    &nginxLocation{Headers: map[string]string{"Cache-Control": "max-age=60"}},
+	}
+	assert.Equal(t, "off", findUseStatic("index.html", nginxGzip{UseStatic: "on"}, locations))
+	assert.Equal(t, "on", findUseStatic("assets/main.js", nginxGzip{UseStatic: "on"}, locations))
+	assert.Equal(t, "", findUseStatic("index.html.map", nginxGzip{}, locations))
+}
+
+func TestThatPrecompressedFilesAreServedLegacy(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.Webapp = &webApplication{StaticContent: "app"}
+	json.Aurora.Precompress = &precompress{Brotli: true}
+	json.ServeBrotli = true
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{runtime.NginxBrotliLabel: "true", runtime.TemplateVersionLabel: "2"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	assert.Contains(t, files["nginx.conf"], `
+          try_files $uri /index.html;
+          gzip_static on;
+          gzip_vary on;
+          brotli_static on;
+       }`)
+}
+
+func TestThatBrotliIsNotServedWithoutFilesOrModule(t *testing.T) {
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	for _, test := range []struct {
+		serveBrotli bool
+		labels      map[string]string
+	}{
+		{serveBrotli: false, labels: map[string]string{runtime.NginxBrotliLabel: "true", runtime.TemplateVersionLabel: "2"}},
+		{serveBrotli: true, labels: map[string]string{runtime.TemplateVersionLabel: "2"}},
+	} {
+		files := make(map[string]string)
+		json := osJson
+		json.Aurora.Webapp = &webApplication{StaticContent: "app"}
+		json.Aurora.Precompress = &precompress{Brotli: true}
+		json.ServeBrotli = test.serveBrotli
+		err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+			Tag:        "latest",
+			Repository: "aurora/wrench",
+		}, ImageInfo: &runtime.ImageInfo{
+			Labels: test.labels,
+		}}, auroraVersion, testFileWriter(files), buildTime)
+
+		assert.NoError(t, err)
+		assert.Contains(t, files["nginx.conf"], "gzip_static on;")
+		assert.NotContains(t, files["nginx.conf"], "brotli_static")
+	}
+}
+
+func TestThatPrecompressedFilesAreServedRadish(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.Webapp = &webApplication{StaticContent: "app"}
+	json.Aurora.Precompress = &precompress{}
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{"www.skatteetaten.no-imageArchitecture": "nodejs"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	data, err := UnmarshallOpenshiftConfig(strings.NewReader(files["nginx-radish.json"]))
+	assert.NoError(t, err)
+	assert.Equal(t, "on", data.Web.Gzip.UseStatic)
+	assert.Nil(t, data.Web.Brotli)
+}
+
+func writeTestFile(t *testing.T, folder string, name string, content string) {
+	path := filepath.Join(folder, filepath.FromSlash(name))
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func assertNoFile(t *testing.T, path string) {
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "Expected no file %s", path)
+}
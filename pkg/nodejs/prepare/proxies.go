@@ -0,0 +1,118 @@
+package prepare
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+// nginxProxy is a location in web.proxies that proxies to an upstream. The host and port of the upstream are read
+// from the environment variables <Upstream>_HOST and <Upstream>_PORT when the container starts. Like PROXY_PASS_HOST
+// and PROXY_PASS_PORT they default to the node.js application
+type nginxProxy struct {
+	Path     string `json:"path"`
+	Upstream string `json:"upstream"`
+	// Optional. Replaces Path in the request to the upstream, so /orders/1 with Rewrite /api/ is sent as /api/1
+	Rewrite   string            `json:"rewrite,omitempty"`
+	Websocket bool              `json:"websocket,omitempty"`
+	Overrides map[string]string `json:"overrides,omitempty"`
+}
+
+var (
+	proxyPathPattern     = regexp.MustCompile(`^/[A-Za-z0-9_/-]*$`)
+	proxyUpstreamPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// validateProxies checks the proxies, and that no proxy has the path of a web application
+func validateProxies(proxies []nginxProxy, webAppPaths []string) error {
+	paths := make(map[string]bool)
+	for _, path := range webAppPaths {
+		paths[withTrailingSlash(path)] = true
+	}
+	for _, proxy := range proxies {
+		if !proxyPathPattern.MatchString(proxy.Path) {
+			return errors.Errorf("Proxy path %s should start with / and contain only letters, digits, _, - and /", proxy.Path)
+		}
+		if proxy.Path == "/" {
+			return errors.New("Proxy path / would hide the static content")
+		}
+		if paths[withTrailingSlash(proxy.Path)] {
+			return errors.Errorf("Proxy path %s is used more than once, or by a web application", proxy.Path)
+		}
+		paths[withTrailingSlash(proxy.Path)] = true
+		if !proxyUpstreamPattern.MatchString(proxy.Upstream) {
+			return errors.Errorf("Upstream %s of proxy %s should be an environment variable prefix like BACKEND", proxy.Upstream, proxy.Path)
+		}
+		if proxy.Rewrite != "" && !proxyPathPattern.MatchString(proxy.Rewrite) {
+			return errors.Errorf("Rewrite %s of proxy %s should start with / and contain only letters, digits, _, - and /", proxy.Rewrite, proxy.Path)
+		}
+		if err := whitelistOverrides(proxy.Overrides); err != nil {
+			return errors.Wrapf(err, "Proxy %s", proxy.Path)
+		}
+		for key := range proxy.Overrides {
+			if allowedNginxOverrides[key].Context != apiContext {
+				return errors.Errorf("Config %s is not allowed in proxy %s. Set it in nodejs.overrides", key, proxy.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// buildProxies gives every proxy a path ending with /, and the /api overrides of nodejs.overrides that the proxy
+// does not set itself
+func buildProxies(proxies []nginxProxy, apiOverrides map[string]string) []nginxProxy {
+	if len(proxies) == 0 {
+		return nil
+	}
+	var built []nginxProxy
+	for _, proxy := range proxies {
+		overrides := make(map[string]string)
+		for key, value := range apiOverrides {
+			overrides[key] = value
+		}
+		for key, value := range proxy.Overrides {
+			overrides[key] = value
+		}
+		if len(overrides) == 0 {
+			overrides = nil
+		}
+		built = append(built, nginxProxy{
+			Path:      withTrailingSlash(proxy.Path),
+			Upstream:  proxy.Upstream,
+			Rewrite:   withTrailingSlash(proxy.Rewrite),
+			Websocket: proxy.Websocket,
+			Overrides: overrides,
+		})
+	}
+	return built
+}
+
+// hasProxy tells if a proxy has the path, with or without a trailing slash
+func hasProxy(proxies []nginxProxy, path string) bool {
+	for _, proxy := range proxies {
+		if withTrailingSlash(proxy.Path) == withTrailingSlash(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// addUpstreamDefaults lets the upstreams default to the node.js application, unless docker.env sets them
+func addUpstreamDefaults(proxies []nginxProxy, env map[string]string) {
+	for _, proxy := range proxies {
+		if _, exists := env[proxy.Upstream+"_HOST"]; !exists {
+			env[proxy.Upstream+"_HOST"] = "localhost"
+		}
+		if _, exists := env[proxy.Upstream+"_PORT"]; !exists {
+			env[proxy.Upstream+"_PORT"] = "9090"
+		}
+	}
+}
+
+// withTrailingSlash makes /orders and /orders/ the same location, like the path of the webapp
+func withTrailingSlash(path string) string {
+	if path == "" || strings.HasSuffix(path, "/") {
+		return path
+	}
+	return path + "/"
+}
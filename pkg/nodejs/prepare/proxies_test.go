@@ -0,0 +1,141 @@
+package prepare
+
+import (
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var testProxies = []nginxProxy{
+	{Path: "/orders", Upstream: "ORDERS", Rewrite: "/api/v1", Overrides: map[string]string{"proxy_read_timeout": "120s"}},
+	{Path: "/events/", Upstream: "EVENTS", Websocket: true},
+}
+
+func TestThatProxiesAreWrittenBeforeTheApiLocationLegacy(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.NodeJS = &nodeJSApplication{Overrides: map[string]string{"client_max_body_size": "5m"}}
+	json.Aurora.Proxies = testProxies
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{runtime.TemplateVersionLabel: "2"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	assert.Contains(t, files["nginx.conf"], `
+    server {
+       listen 8080;
+
+       location /orders/ {
+          rewrite ^/orders/(.*)$ /api/v1/$1 break;
+          proxy_pass http://${ORDERS_HOST}:${ORDERS_PORT};
+          client_max_body_size 5m;
+          proxy_read_timeout 120s;
+       }
+
+       location /events/ {
+          proxy_pass http://${EVENTS_HOST}:${EVENTS_PORT};
+          proxy_http_version 1.1;
+          proxy_set_header Upgrade $http_upgrade;
+          proxy_set_header Connection "upgrade";
+          client_max_body_size 5m;
+       }
+
+       location /api {
+          return 404;
+          client_max_body_size 5m;
+       }
+
+       location / {`)
+	assert.Contains(t, files["Dockerfile"], `EVENTS_HOST="localhost"`)
+	assert.Contains(t, files["Dockerfile"], `ORDERS_PORT="9090"`)
+}
+
+func TestThatAProxyCanReplaceTheApiLocationLegacy(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.NodeJS = &nodeJSApplication{Main: "test.json"}
+	json.Aurora.Proxies = []nginxProxy{{Path: "/api", Upstream: "BACKEND"}}
+	json.DockerMetadata.Env = map[string]string{"BACKEND_HOST": "backend"}
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{runtime.TemplateVersionLabel: "2"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	assert.Contains(t, files["nginx.conf"], `
+       location /api/ {
+          proxy_pass http://${BACKEND_HOST}:${BACKEND_PORT};
+       }
+`)
+	assert.NotContains(t, files["nginx.conf"], "location /api {")
+	assert.Contains(t, files["Dockerfile"], `BACKEND_HOST="backend"`)
+	assert.Contains(t, files["Dockerfile"], `BACKEND_PORT="9090"`)
+}
+
+func TestThatProxiesAreSetInRadishConfig(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.NodeJS = &nodeJSApplication{}
+	json.Aurora.Proxies = testProxies
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{"www.skatteetaten.no-imageArchitecture": "nodejs"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	data, err := UnmarshallOpenshiftConfig(strings.NewReader(files["nginx-radish.json"]))
+	assert.NoError(t, err)
+	assert.Equal(t, []nginxProxy{
+		{Path: "/orders/", Upstream: "ORDERS", Rewrite: "/api/v1/", Overrides: map[string]string{"proxy_read_timeout": "120s"}},
+		{Path: "/events/", Upstream: "EVENTS", Websocket: true},
+	}, data.Web.Proxies)
+}
+
+func TestThatApiLocationIsTheDefault(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{"www.skatteetaten.no-imageArchitecture": "nodejs"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, files["nginx-radish.json"], "proxies")
+}
+
+func TestValidateProxies(t *testing.T) {
+	assert.NoError(t, validateProxies(nil, []string{"/"}))
+	assert.NoError(t, validateProxies(testProxies, []string{"/", "/admin/"}))
+
+	assert.EqualError(t, validateProxies([]nginxProxy{{Path: "orders", Upstream: "ORDERS"}}, nil),
+		"Proxy path orders should start with / and contain only letters, digits, _, - and /")
+	assert.EqualError(t, validateProxies([]nginxProxy{{Path: "/", Upstream: "ORDERS"}}, nil),
+		"Proxy path / would hide the static content")
+	assert.EqualError(t, validateProxies([]nginxProxy{{Path: "/a", Upstream: "A"}, {Path: "/a/", Upstream: "B"}}, nil),
+		"Proxy path /a/ is used more than once, or by a web application")
+	assert.EqualError(t, validateProxies([]nginxProxy{{Path: "/admin", Upstream: "A"}}, []string{"/", "/admin/"}),
+		"Proxy path /admin is used more than once, or by a web application")
+	assert.EqualError(t, validateProxies([]nginxProxy{{Path: "/a", Upstream: "a;"}}, nil),
+		"Upstream a; of proxy /a should be an environment variable prefix like BACKEND")
+	assert.EqualError(t, validateProxies([]nginxProxy{{Path: "/a", Upstream: "A", Rewrite: "/b c"}}, nil),
+		"Rewrite /b c of proxy /a should start with / and contain only letters, digits, _, - and /")
+	assert.EqualError(t, validateProxies([]nginxProxy{{Path: "/a", Upstream: "A", Overrides: map[string]string{"proxy_read_timeout": "1h"}}}, nil),
+		"Proxy /a: Value on proxy_read_timeout should be on the form Ns where N is between 1 and 300")
+	assert.EqualError(t, validateProxies([]nginxProxy{{Path: "/a", Upstream: "A", Overrides: map[string]string{"keepalive_timeout": "10s"}}}, nil),
+		"Config keepalive_timeout is not allowed in proxy /a. Set it in nodejs.overrides")
+}
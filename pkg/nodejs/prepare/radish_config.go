@@ -2,6 +2,7 @@ package prepare
 
 import (
 	"encoding/json"
+	"github.com/skatteetaten/architect/pkg/templates"
 	"github.com/skatteetaten/architect/pkg/util"
 	"io"
 )
@@ -14,6 +15,13 @@ type Web struct {
 	Gzip              nginxGzip      `json:"gzip"`
 	Exclude           []string       `json:"exclude"`
 	Locations         nginxLocations `json:"locations"`
+	Brotli            *nginxBrotli   `json:"brotli,omitempty"`
+	// The overrides in the server block. The overrides of the /api location are in Nodejs
+	Overrides map[string]string `json:"overrides,omitempty"`
+	// Replaces the /api location when set
+	Proxies []nginxProxy `json:"proxies,omitempty"`
+	// The web applications after WebApp
+	WebApps []WebApp `json:"webapps,omitempty"`
 }
 
 //Nodejs :
@@ -28,6 +36,11 @@ type WebApp struct {
 	Path            string            `json:"path"`
 	DisableTryfiles bool              `json:"disableTryfiles"`
 	Headers         map[string]string `json:"headers"`
+	// The files matching FingerprintPattern get ImmutableHeaders instead of Headers
+	FingerprintPattern string            `json:"fingerprintPattern,omitempty"`
+	ImmutableHeaders   map[string]string `json:"immutableHeaders,omitempty"`
+	// The overrides in the static location
+	Overrides map[string]string `json:"overrides,omitempty"`
 }
 
 //OpenshiftConfig :
@@ -41,7 +54,7 @@ func UnmarshallOpenshiftConfig(buffer io.Reader) (OpenshiftConfig, error) {
 	return data, err
 }
 
-func newRadishNginxConfig(docker *DockerfileData, nginx *NginxfileData) util.WriterFunc {
+func newRadishNginxConfig(docker *DockerfileData, nginx *NginxfileData, templateSet *templates.TemplateSet) util.WriterFunc {
 	return func(writer io.Writer) error {
 		data := OpenshiftConfig{
 			Web: Web{
@@ -51,16 +64,35 @@ func newRadishNginxConfig(docker *DockerfileData, nginx *NginxfileData) util.Wri
 					Overrides: nginx.NginxOverrides,
 				},
 				WebApp: WebApp{
-					Content:         docker.Static,
-					Path:            nginx.Path,
-					DisableTryfiles: !nginx.SPA,
-					Headers:         nginx.ExtraStaticHeaders,
+					Content:            docker.Static,
+					Path:               nginx.Path,
+					DisableTryfiles:    !nginx.SPA,
+					Headers:            nginx.ExtraStaticHeaders,
+					FingerprintPattern: nginx.FingerprintPattern,
+					ImmutableHeaders:   nginx.ImmutableHeaders,
+					Overrides:          nginx.StaticOverrides,
 				},
 				Gzip:      nginx.Gzip,
 				Exclude:   nginx.Exclude,
 				Locations: nginx.Locations,
+				Overrides: nginx.ServerOverrides,
+				Proxies:   nginx.Proxies,
 			},
 		}
+		for _, webapp := range nginx.WebApps {
+			data.Web.WebApps = append(data.Web.WebApps, WebApp{
+				Content:            webapp.Content,
+				Path:               webapp.Path,
+				DisableTryfiles:    !webapp.SPA,
+				Headers:            webapp.ExtraStaticHeaders,
+				FingerprintPattern: webapp.FingerprintPattern,
+				ImmutableHeaders:   webapp.ImmutableHeaders,
+				Overrides:          webapp.StaticOverrides,
+			})
+		}
+		if nginx.Brotli {
+			data.Web.Brotli = &nginxBrotli{UseStatic: "on"}
+		}
 		err := json.NewEncoder(writer).Encode(data)
 		return err
 	}
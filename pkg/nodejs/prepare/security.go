@@ -0,0 +1,152 @@
+package prepare
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The values of webapp.security in openshift.json
+const (
+	SecurityOff      = "off"
+	SecurityStandard = "standard"
+	SecurityStrict   = "strict"
+)
+
+const contentSecurityPolicy = "Content-Security-Policy"
+
+var securityPresets = map[string]map[string]string{
+	SecurityOff: {},
+	SecurityStandard: {
+		"X-Frame-Options":           "SAMEORIGIN",
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+		"Strict-Transport-Security": "max-age=31536000",
+	},
+	SecurityStrict: {
+		"X-Frame-Options":           "DENY",
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           "no-referrer",
+		"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+	},
+}
+
+// The strict preset starts from this policy. webapp.contentSecurityPolicy replaces its directives
+var strictContentSecurityPolicy = map[string][]string{
+	"default-src":     {"'self'"},
+	"base-uri":        {"'self'"},
+	"object-src":      {"'none'"},
+	"frame-ancestors": {"'none'"},
+}
+
+var (
+	cspDirectivePattern = regexp.MustCompile(`^[a-z][a-z-]*$`)
+	// The policy is written in quotes in nginx.conf, and ; separates the directives
+	illegalCspSourceCharacters = regexp.MustCompile(`["\s;,]`)
+)
+
+/*
+securityHeaders expands webapp.security and webapp.contentSecurityPolicy into headers. The headers are added to the
+static content and every location, since a location with headers of its own does not get the headers of the server
+in nginx. The headers in openshift.json win over the preset.
+*/
+func securityHeaders(webapp *webApplication) (map[string]string, error) {
+	if webapp == nil {
+		return nil, nil
+	}
+	security := webapp.Security
+	if security == "" {
+		security = SecurityOff
+	}
+	preset, ok := securityPresets[security]
+	if !ok {
+		return nil, errors.Errorf("Security %s is not supported. Use %s, %s or %s", webapp.Security,
+			SecurityStrict, SecurityStandard, SecurityOff)
+	}
+	headers := make(map[string]string)
+	for key, value := range preset {
+		headers[key] = value
+	}
+
+	directives := make(map[string][]string)
+	if security == SecurityStrict {
+		for directive, sources := range strictContentSecurityPolicy {
+			directives[directive] = sources
+		}
+	}
+	for directive, sources := range webapp.ContentSecurityPolicy {
+		directives[directive] = sources
+	}
+	policy, err := buildContentSecurityPolicy(directives)
+	if err != nil {
+		return nil, err
+	}
+	if policy != "" {
+		headers[contentSecurityPolicy] = policy
+	}
+	return headers, nil
+}
+
+// buildContentSecurityPolicy writes default-src first, and the other directives sorted by name
+func buildContentSecurityPolicy(directives map[string][]string) (string, error) {
+	var names []string
+	for directive, sources := range directives {
+		if !cspDirectivePattern.MatchString(directive) {
+			return "", errors.Errorf("%s is not a Content-Security-Policy directive", directive)
+		}
+		for _, source := range sources {
+			if source == "" || illegalCspSourceCharacters.MatchString(source) {
+				return "", errors.Errorf("Source %q of %s can not be empty or contain quotes, whitespace, ; or ,",
+					source, directive)
+			}
+		}
+		names = append(names, directive)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "default-src" || names[j] == "default-src" {
+			return names[i] == "default-src"
+		}
+		return names[i] < names[j]
+	})
+
+	var policy []string
+	for _, directive := range names {
+		policy = append(policy, strings.Join(append([]string{directive}, directives[directive]...), " "))
+	}
+	return strings.Join(policy, "; "), nil
+}
+
+// withSecurityHeaders adds the security headers that are not set in headers, whatever their case
+func withSecurityHeaders(security map[string]string, headers map[string]string) map[string]string {
+	if len(security) == 0 {
+		return headers
+	}
+	merged := make(map[string]string)
+	for key, value := range security {
+		merged[key] = value
+	}
+	for key, value := range headers {
+		for securityKey := range security {
+			if strings.EqualFold(key, securityKey) {
+				delete(merged, securityKey)
+			}
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// overriddenSecurityHeaders returns the security headers with the values set in headers, so the locations get the
+// same security headers as the static content
+func overriddenSecurityHeaders(security map[string]string, headers map[string]string) map[string]string {
+	overridden := make(map[string]string)
+	for key, value := range withSecurityHeaders(security, headers) {
+		for securityKey := range security {
+			if strings.EqualFold(key, securityKey) {
+				overridden[key] = value
+			}
+		}
+	}
+	return overridden
+}
@@ -0,0 +1,120 @@
+package prepare
+
+import (
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestThatSecurityIsOffByDefault(t *testing.T) {
+	headers, err := securityHeaders(&webApplication{})
+	assert.NoError(t, err)
+	assert.Empty(t, headers)
+}
+
+func TestThatStrictSecurityHasAContentSecurityPolicy(t *testing.T) {
+	headers, err := securityHeaders(&webApplication{
+		Security: SecurityStrict,
+		ContentSecurityPolicy: map[string][]string{
+			"img-src":    {"'self'", "data:"},
+			"object-src": {"'self'"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "DENY", headers["X-Frame-Options"])
+	assert.Equal(t, "default-src 'self'; base-uri 'self'; frame-ancestors 'none'; img-src 'self' data:; object-src 'self'",
+		headers["Content-Security-Policy"])
+}
+
+func TestThatStandardSecurityOnlyHasTheGivenPolicy(t *testing.T) {
+	headers, err := securityHeaders(&webApplication{Security: SecurityStandard})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"X-Frame-Options":           "SAMEORIGIN",
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+		"Strict-Transport-Security": "max-age=31536000",
+	}, headers)
+
+	headers, err = securityHeaders(&webApplication{
+		Security:              SecurityOff,
+		ContentSecurityPolicy: map[string][]string{"upgrade-insecure-requests": {}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"Content-Security-Policy": "upgrade-insecure-requests"}, headers)
+}
+
+func TestThatInvalidSecurityIsRejected(t *testing.T) {
+	_, err := securityHeaders(&webApplication{Security: "paranoid"})
+	assert.EqualError(t, err, "Security paranoid is not supported. Use strict, standard or off")
+
+	_, err = securityHeaders(&webApplication{ContentSecurityPolicy: map[string][]string{"script-src; x": {"'self'"}}})
+	assert.EqualError(t, err, "script-src; x is not a Content-Security-Policy directive")
+
+	_, err = securityHeaders(&webApplication{ContentSecurityPolicy: map[string][]string{"script-src": {`'self'"`}}})
+	assert.EqualError(t, err, `Source "'self'\"" of script-src can not be empty or contain quotes, whitespace, ; or ,`)
+}
+
+func TestThatExplicitHeadersWinOverSecurityLegacy(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.Webapp = &webApplication{
+		StaticContent: "app",
+		Security:      SecurityStandard,
+		Headers:       map[string]string{"x-frame-options": "DENY"},
+	}
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	assert.Contains(t, files["nginx.conf"], `
+          add_header Referrer-Policy "strict-origin-when-cross-origin";
+          add_header Strict-Transport-Security "max-age=31536000";
+          add_header X-Content-Type-Options "nosniff";
+          add_header x-frame-options "DENY";
+       }`)
+	assert.NotContains(t, files["nginx.conf"], "SAMEORIGIN")
+}
+
+func TestThatLocationsGetSecurityHeadersRadish(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora.Webapp = &webApplication{
+		StaticContent: "app",
+		Security:      SecurityStrict,
+		Headers:       map[string]string{"Referrer-Policy": "same-origin"},
+	}
+	json.Aurora.Locations = map[string]interface{}{
+		"index.html": map[string]interface{}{
+			"headers": map[string]interface{}{"X-Frame-Options": "SAMEORIGIN", "Cache-Control": "no-store"},
+		},
+	}
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{"www.skatteetaten.no-imageArchitecture": "nodejs"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	data, err := UnmarshallOpenshiftConfig(strings.NewReader(files["nginx-radish.json"]))
+	assert.NoError(t, err)
+	assert.Equal(t, "DENY", data.Web.WebApp.Headers["X-Frame-Options"])
+	assert.Equal(t, "same-origin", data.Web.WebApp.Headers["Referrer-Policy"])
+	assert.Equal(t, map[string]string{
+		"Cache-Control":             "no-store",
+		"Content-Security-Policy":   "default-src 'self'; base-uri 'self'; frame-ancestors 'none'; object-src 'none'",
+		"Referrer-Policy":           "same-origin",
+		"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "SAMEORIGIN",
+	}, data.Web.Locations["index.html"].Headers)
+}
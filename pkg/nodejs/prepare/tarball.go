@@ -2,6 +2,8 @@ package prepare
 
 import (
 	"archive/tar"
+	"bufio"
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"github.com/pkg/errors"
@@ -10,117 +12,147 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
-func extractTarball(pathToTarball string) (string, error) {
-	tmpdir, err := ioutil.TempDir("", "nodejs-architect")
+const openshiftJsonInTarball = "package/metadata/openshift.json"
+
+// Size of the buffers used when streaming the tarball. Shared by all entries in one extraction.
+const tarballBufferSize = 64 * 1024
+
+/*
+extractTarball streams the gzipped tarball into targetFolder in one single pass. The openshift.json
+is captured on the way and validated as soon as it is seen, so a broken deliverable fails before
+the rest of the archive is written.
+*/
+func extractTarball(pathToTarball string, targetFolder string) (*openshiftJson, error) {
 	tarball, err := os.Open(pathToTarball)
 	if err != nil {
-		return "", errors.Wrap(err, "Error opening tarball")
+		return nil, errors.Wrap(err, "Error opening tarball")
 	}
 	defer tarball.Close()
-	gzipStream, err := gzip.NewReader(tarball)
+
+	gzipStream, err := gzip.NewReader(bufio.NewReaderSize(tarball, tarballBufferSize))
 	if err != nil {
-		return "", err
+		return nil, errors.Wrap(err, "Error reading gzip stream")
 	}
-
 	defer gzipStream.Close()
 
 	tarReader := tar.NewReader(gzipStream)
+	copyBuffer := make([]byte, tarballBufferSize)
+	createdDirs := make(map[string]bool)
 
-	for true {
+	var v *openshiftJson
+	for {
 		header, err := tarReader.Next()
 
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return "", errors.Wrapf(err, "Error extracting tarball")
+			return nil, errors.Wrapf(err, "Error extracting tarball")
 		} else if header == nil {
 			continue
 		}
 
-		name := header.Name
-
-		target := filepath.Join(tmpdir, header.Name)
+		target, err := tarballTarget(targetFolder, header.Name)
+		if err != nil {
+			return nil, err
+		}
 
-		dirname := filepath.Dir(target)
 		switch header.Typeflag {
 		case tar.TypeDir:
-			if _, err := os.Stat(target); err != nil {
-				if err := os.MkdirAll(target, 0755); err != nil {
-					return tmpdir, errors.Wrapf(err, "Error writing file %s", name)
-				}
+			if err := mkdirAll(target, createdDirs); err != nil {
+				return nil, errors.Wrapf(err, "Error writing file %s", header.Name)
 			}
 		case tar.TypeReg: // = regular file
-			ret, err := writeInternal(func() (string, error) {
-				if _, err := os.Stat(dirname); err != nil {
-					if err := os.MkdirAll(dirname, 0755); err != nil {
-						return tmpdir, errors.Wrapf(err, "Error writing file %s", name)
-					}
-				}
-				f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-				if err != nil {
-					return tmpdir, errors.Wrapf(err, "Error writing file %s", name)
-				}
-				defer f.Close()
-
-				// copy over contents
-
-				if _, err := io.Copy(f, tarReader); err != nil {
-					return tmpdir, errors.Wrapf(err, "Error writing file %s", name)
+			if err := mkdirAll(filepath.Dir(target), createdDirs); err != nil {
+				return nil, errors.Wrapf(err, "Error writing file %s", header.Name)
+			}
+			if header.Name != openshiftJsonInTarball {
+				if err := writeTarEntry(target, os.FileMode(header.Mode), tarReader, copyBuffer); err != nil {
+					return nil, errors.Wrapf(err, "Error writing file %s", header.Name)
 				}
-				return tmpdir, nil
-			})
-			if err != nil {
-				return ret, err
+				continue
 			}
 
+			metadata := new(bytes.Buffer)
+			if err := writeTarEntry(target, os.FileMode(header.Mode), io.TeeReader(tarReader, metadata), copyBuffer); err != nil {
+				return nil, errors.Wrapf(err, "Error writing file %s", header.Name)
+			}
+			v = &openshiftJson{}
+			if err := json.NewDecoder(metadata).Decode(v); err != nil {
+				return nil, errors.Wrap(err, "Error reading openshift.json")
+			}
+			if err := validateOpenshiftJson(v); err != nil {
+				return nil, errors.Wrap(err, "Error validating openshift.json")
+			}
 		default:
 			logrus.Infof("Dont support %c", header.Typeflag)
 		}
 	}
-	return tmpdir, nil
+
+	if v == nil {
+		return nil, errors.New("Did not find any openshift.json in archive. Wrong format?")
+	}
+	return v, nil
 }
 
-//Probably a better way of doing this.. But need to have file.Close() called to prevent to many open fd's.
-func writeInternal(deferrerd func() (string, error)) (string, error) {
-	return deferrerd()
+// Need to have file.Close() called per entry to prevent to many open fd's.
+func writeTarEntry(target string, mode os.FileMode, reader io.Reader, buffer []byte) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.CopyBuffer(f, reader, buffer)
+	return err
+}
+
+func mkdirAll(dir string, createdDirs map[string]bool) error {
+	if createdDirs[dir] {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	createdDirs[dir] = true
+	return nil
 }
 
-func findOpenshiftJsonInTarball(pathToTarball string) (*openshiftJson, error) {
+// We write straight into the build context, so entries are not allowed to escape it
+func tarballTarget(targetFolder string, name string) (string, error) {
+	target := filepath.Join(targetFolder, name)
+	if target != filepath.Clean(targetFolder) && !strings.HasPrefix(target, filepath.Clean(targetFolder)+string(os.PathSeparator)) {
+		return "", errors.Errorf("Illegal path %s in tarball", name)
+	}
+	return target, nil
+}
+
+// readFileInTarball reads a single file in the gzipped tarball without extracting it. It returns nil if the file is missing
+func readFileInTarball(pathToTarball string, name string) ([]byte, error) {
 	tarball, err := os.Open(pathToTarball)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error opening tarball")
 	}
 	defer tarball.Close()
-	gzipStream, err := gzip.NewReader(tarball)
+
+	gzipStream, err := gzip.NewReader(bufio.NewReaderSize(tarball, tarballBufferSize))
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, "Error reading gzip stream")
 	}
-
 	defer gzipStream.Close()
 
 	tarReader := tar.NewReader(gzipStream)
-
-	for true {
+	for {
 		header, err := tarReader.Next()
-
 		if err == io.EOF {
-			break
+			return nil, nil
 		} else if err != nil {
-			return nil, errors.Wrapf(err, "Error extracting tarball")
-		} else if header == nil {
-			continue
+			return nil, errors.Wrap(err, "Error reading tarball")
 		}
-
-		if header.Typeflag == tar.TypeReg && header.Name == "package/metadata/openshift.json" {
-			v := &openshiftJson{}
-			err := json.NewDecoder(tarReader).Decode(v)
-			if err != nil {
-				return nil, errors.Wrap(err, "Error reading openshift.json")
-			}
-			return v, nil
+		if header.Typeflag == tar.TypeReg && strings.TrimPrefix(header.Name, "./") == name {
+			return ioutil.ReadAll(tarReader)
 		}
 	}
-	return nil, errors.New("Did not find any openshift.json in archive. Wrong format?")
 }
@@ -0,0 +1,86 @@
+package prepare
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractTarballCapturesOpenshiftJson(t *testing.T) {
+	target, err := ioutil.TempDir("", "nodejs-architect-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(target)
+
+	v, err := extractTarball("testfiles/openshift-referanse-react-snapshot_test-SNAPSHOT-Webleveransepakke.tgz", target)
+	assert.NoError(t, err)
+	assert.Equal(t, "api/server.js", v.Aurora.NodeJS.Main)
+	assert.Equal(t, "build", v.Aurora.Static)
+
+	for _, file := range []string{"package/metadata/openshift.json", "package/build/index.html", "package/api/server.js"} {
+		_, err := os.Stat(filepath.Join(target, file))
+		assert.NoError(t, err, file)
+	}
+}
+
+func TestExtractTarballFailsWithoutOpenshiftJson(t *testing.T) {
+	tarball := writeTestTarball(t, map[string]string{"package/index.html": "<html></html>"})
+	defer os.Remove(tarball)
+	target, err := ioutil.TempDir("", "nodejs-architect-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(target)
+
+	_, err = extractTarball(tarball, target)
+	assert.EqualError(t, err, "Did not find any openshift.json in archive. Wrong format?")
+}
+
+func TestExtractTarballValidatesOpenshiftJson(t *testing.T) {
+	tarball := writeTestTarball(t, map[string]string{
+		openshiftJsonInTarball: `{"web": {"nodejs": {"main": "a.js", "overrides": {"worker_processes": "8"}}}}`,
+	})
+	defer os.Remove(tarball)
+	target, err := ioutil.TempDir("", "nodejs-architect-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(target)
+
+	_, err = extractTarball(tarball, target)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Error validating openshift.json: Config worker_processes is not allowed to override with Architect.")
+}
+
+func TestExtractTarballRejectsPathsOutsideTarget(t *testing.T) {
+	tarball := writeTestTarball(t, map[string]string{"../evil.sh": "#!/bin/sh"})
+	defer os.Remove(tarball)
+	target, err := ioutil.TempDir("", "nodejs-architect-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(target)
+
+	_, err = extractTarball(tarball, target)
+	assert.EqualError(t, err, "Illegal path ../evil.sh in tarball")
+}
+
+func writeTestTarball(t *testing.T, files map[string]string) string {
+	f, err := ioutil.TempFile("", "nodejs-architect-test*.tgz")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	gzipWriter := gzip.NewWriter(f)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tarWriter.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+	return f.Name()
+}
@@ -1,6 +1,10 @@
 package prepare
 
-import "github.com/skatteetaten/architect/pkg/config/runtime"
+import (
+	"encoding/json"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/skatteetaten/architect/pkg/docker"
+)
 
 type auroraApplication struct {
 	NodeJS *nodeJSApplication `json:"nodejs"`
@@ -11,6 +15,9 @@ type auroraApplication struct {
 	Gzip              nginxGzip              `json:"gzip"`
 	Locations         map[string]interface{} `json:"locations"`
 	Exclude           []string               `json:"exclude"`
+	Precompress       *precompress           `json:"precompress"`
+	Proxies           []nginxProxy           `json:"proxies"`
+	Webapps           []webApplication       `json:"webapps"`
 	//Deprecated
 	Path string `json:"path"`
 	//Deprecated
@@ -22,6 +29,14 @@ type webApplication struct {
 	Path            string            `json:"path"`
 	Headers         map[string]string `json:"headers"`
 	DisableTryfiles bool              `json:"disableTryfiles"`
+	// Optional. none or fingerprinted
+	CacheStrategy string `json:"cacheStrategy"`
+	// Optional. The files cached as immutable with the fingerprinted cache strategy
+	FingerprintPattern string `json:"fingerprintPattern"`
+	// Optional. strict, standard or off
+	Security string `json:"security"`
+	// Optional. The sources of each Content-Security-Policy directive
+	ContentSecurityPolicy map[string][]string `json:"contentSecurityPolicy"`
 }
 
 type nodeJSApplication struct {
@@ -32,11 +47,17 @@ type nodeJSApplication struct {
 type openshiftJson struct {
 	Aurora         auroraApplication `json:"web"`
 	DockerMetadata dockerMetadata    `json:"docker"`
+	// The git metadata in the package. Not part of openshift.json
+	BuildInfo *docker.BuildInfo `json:"-"`
+	// Set when .br files were written to the static content and the nginx of the base image can serve them
+	ServeBrotli bool `json:"-"`
 }
 
 type dockerMetadata struct {
 	Maintainer string            `json:"maintainer"`
 	Labels     map[string]string `json:"labels"`
+	docker.DeliverableEnv
+	Extensions map[string]json.RawMessage `json:"extensions"`
 }
 
 type PreparedImage struct {
@@ -60,6 +81,10 @@ type nginxGzip struct {
 	UseStatic string `json:"use_static"`
 }
 
+type nginxBrotli struct {
+	UseStatic string `json:"use_static"`
+}
+
 type templateInput struct {
 	Baseimage            string
 	HasNodeJSApplication bool
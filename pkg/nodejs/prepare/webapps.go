@@ -0,0 +1,122 @@
+package prepare
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+// webAppLocation is a web application in web.webapps, served by the same nginx as the one in web.webapp. The fields
+// have the names of the fields in NginxfileData, so nginx.conf writes both with the same template
+type webAppLocation struct {
+	Content            string
+	Path               string
+	SPA                bool
+	ExtraStaticHeaders map[string]string
+	FingerprintPattern string
+	ImmutableHeaders   map[string]string
+	// The same for every web application
+	Precompress     bool
+	Brotli          bool
+	StaticOverrides map[string]string
+}
+
+// webApplications returns web.webapp followed by web.webapps. The first one is the main web application
+func webApplications(a auroraApplication) []*webApplication {
+	var webapps []*webApplication
+	if a.Webapp != nil {
+		webapps = append(webapps, a.Webapp)
+	}
+	for i := range a.Webapps {
+		webapps = append(webapps, &a.Webapps[i])
+	}
+	return webapps
+}
+
+// webAppPaths are the paths of web.webapp and web.webapps, or the deprecated web.path without them
+func webAppPaths(a auroraApplication) []string {
+	webapps := webApplications(a)
+	if len(webapps) == 0 {
+		return []string{webAppPath(a.Path)}
+	}
+	var paths []string
+	for _, webapp := range webapps {
+		paths = append(paths, webAppPath(webapp.Path))
+	}
+	return paths
+}
+
+// webAppPath makes the path of a web application start and end with /
+func webAppPath(path string) string {
+	path = "/" + strings.TrimPrefix(path, "/")
+	if !strings.HasSuffix(path, "/") {
+		path = path + "/"
+	}
+	return path
+}
+
+// newWebAppLocation returns the location of the web application, and the security headers it was given
+func newWebAppLocation(webapp *webApplication) (webAppLocation, map[string]string, error) {
+	security, err := securityHeaders(webapp)
+	if err != nil {
+		return webAppLocation{}, nil, err
+	}
+	location := webAppLocation{
+		Content:            webapp.StaticContent,
+		Path:               webAppPath(webapp.Path),
+		SPA:                !webapp.DisableTryfiles,
+		ExtraStaticHeaders: withSecurityHeaders(security, webapp.Headers),
+		FingerprintPattern: fingerprintPattern(webapp),
+	}
+	if location.FingerprintPattern != "" {
+		location.ExtraStaticHeaders, location.ImmutableHeaders = cacheHeaders(location.ExtraStaticHeaders)
+	}
+	return location, security, nil
+}
+
+// The paths and static content are written to nginx.conf and the Dockerfile, so they cannot have spaces or newlines
+var webAppPattern = regexp.MustCompile(`^[A-Za-z0-9_./-]*$`)
+
+func validateWebApps(a auroraApplication) error {
+	if !webAppPattern.MatchString(a.Path) || strings.Contains(a.Path, "..") {
+		return errors.Errorf("Web application path %q should contain only letters, digits, _, -, . and /", a.Path)
+	}
+	paths := make(map[string]bool)
+	for _, webapp := range webApplications(a) {
+		if !webAppPattern.MatchString(webapp.Path) || strings.Contains(webapp.Path, "..") {
+			return errors.Errorf("Web application path %q should contain only letters, digits, _, -, . and /", webapp.Path)
+		}
+		if err := validateCacheStrategy(webapp); err != nil {
+			return err
+		}
+		if _, err := securityHeaders(webapp); err != nil {
+			return err
+		}
+		if paths[webAppPath(webapp.Path)] {
+			return errors.Errorf("Web application path %s is used more than once", webAppPath(webapp.Path))
+		}
+		paths[webAppPath(webapp.Path)] = true
+	}
+	for _, content := range staticContents(a) {
+		if strings.Contains(content, "..") {
+			return errors.Errorf("Static content %s must be a folder inside the package", content)
+		}
+		if !webAppPattern.MatchString(content) {
+			return errors.Errorf("Static content %q should contain only letters, digits, _, -, . and /", content)
+		}
+	}
+	return nil
+}
+
+// staticContents are the folders with the static content in the package
+func staticContents(a auroraApplication) []string {
+	webapps := webApplications(a)
+	if len(webapps) == 0 {
+		return []string{a.Static}
+	}
+	var contents []string
+	for _, webapp := range webapps {
+		contents = append(contents, webapp.StaticContent)
+	}
+	return contents
+}
@@ -0,0 +1,133 @@
+package prepare
+
+import (
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func testWebApps() auroraApplication {
+	return auroraApplication{
+		NodeJS: &nodeJSApplication{},
+		Webapp: &webApplication{StaticContent: "app", Headers: map[string]string{"X-Test": "app"}},
+		Webapps: []webApplication{
+			{StaticContent: "docs", Path: "docs", DisableTryfiles: true},
+			{StaticContent: "admin/build", Path: "/admin/", Security: SecurityStandard, CacheStrategy: CacheStrategyFingerprinted},
+		},
+	}
+}
+
+func TestThatAllWebAppsAreServedLegacy(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora = testWebApps()
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{runtime.TemplateVersionLabel: "2"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	assert.Contains(t, files["Dockerfile"], `
+COPY ./package/app /u01/static/
+COPY ./package/docs /u01/static/docs/
+COPY ./package/admin/build /u01/static/admin/
+
+`)
+	assert.Contains(t, files["nginx.conf"], `
+       location / {
+          root /u01/static;
+          try_files $uri /index.html;
+          add_header X-Test "app";
+       }
+
+       location /docs/ {
+          root /u01/static;
+       }
+
+       location /admin/ {
+          root /u01/static;
+          try_files $uri /admin/index.html;
+          add_header Cache-Control "no-cache";
+          add_header Referrer-Policy "strict-origin-when-cross-origin";
+          add_header Strict-Transport-Security "max-age=31536000";
+          add_header X-Content-Type-Options "nosniff";
+          add_header X-Frame-Options "SAMEORIGIN";
+
+          location ~ "[.-][0-9a-f]{6,}\.[a-z0-9]+$" {
+             add_header Cache-Control "public, max-age=31536000, immutable";
+             add_header Referrer-Policy "strict-origin-when-cross-origin";
+             add_header Strict-Transport-Security "max-age=31536000";
+             add_header X-Content-Type-Options "nosniff";
+             add_header X-Frame-Options "SAMEORIGIN";
+          }
+       }
+    }
+}
+`)
+}
+
+func TestThatAllWebAppsAreSetInRadishConfig(t *testing.T) {
+	files := make(map[string]string)
+	json := osJson
+	json.Aurora = testWebApps()
+	json.Aurora.Webapp = nil
+	auroraVersion := runtime.NewAuroraVersion("1.2.3", false, "1.2.3", runtime.CompleteVersion("1.2.3-b--baseimageversion"))
+	err := prepareImage(config.DockerSpec{}, &json, runtime.BaseImage{DockerImage: runtime.DockerImage{
+		Tag:        "latest",
+		Repository: "aurora/wrench",
+	}, ImageInfo: &runtime.ImageInfo{
+		Labels: map[string]string{"www.skatteetaten.no-imageArchitecture": "nodejs", runtime.TemplateVersionLabel: "2"},
+	}}, auroraVersion, testFileWriter(files), buildTime)
+
+	assert.NoError(t, err)
+	data, err := UnmarshallOpenshiftConfig(strings.NewReader(files["nginx-radish.json"]))
+	assert.NoError(t, err)
+	assert.Equal(t, "docs", data.Web.WebApp.Content)
+	assert.Equal(t, "/docs/", data.Web.WebApp.Path)
+	assert.True(t, data.Web.WebApp.DisableTryfiles)
+	assert.Len(t, data.Web.WebApps, 1)
+	assert.Equal(t, "admin/build", data.Web.WebApps[0].Content)
+	assert.Equal(t, "/admin/", data.Web.WebApps[0].Path)
+	assert.False(t, data.Web.WebApps[0].DisableTryfiles)
+	assert.Equal(t, "nosniff", data.Web.WebApps[0].Headers["X-Content-Type-Options"])
+	assert.Equal(t, DefaultFingerprintPattern, data.Web.WebApps[0].FingerprintPattern)
+	assert.Contains(t, files["Dockerfile"], "COPY ./package/admin/build /u01/static/admin/\n")
+}
+
+func TestValidateWebApps(t *testing.T) {
+	assert.NoError(t, validateWebApps(testWebApps()))
+
+	webapps := testWebApps()
+	webapps.Webapps[0].Path = "/"
+	assert.EqualError(t, validateWebApps(webapps), "Web application path / is used more than once")
+
+	webapps = testWebApps()
+	webapps.Webapps[1].StaticContent = "../admin"
+	assert.EqualError(t, validateWebApps(webapps), "Static content ../admin must be a folder inside the package")
+
+	webapps = testWebApps()
+	webapps.Webapps[0].Path = "/docs;\n       return 200"
+	assert.EqualError(t, validateWebApps(webapps),
+		`Web application path "/docs;\n       return 200" should contain only letters, digits, _, -, . and /`)
+
+	webapps = testWebApps()
+	webapps.Webapps[1].StaticContent = "admin /etc/passwd"
+	assert.EqualError(t, validateWebApps(webapps), `Static content "admin /etc/passwd" should contain only letters, digits, _, -, . and /`)
+
+	assert.EqualError(t, validateWebApps(auroraApplication{Path: "/app\nRUN id"}),
+		`Web application path "/app\nRUN id" should contain only letters, digits, _, -, . and /`)
+
+	webapps = testWebApps()
+	webapps.Webapps[1].Security = "paranoid"
+	assert.EqualError(t, validateWebApps(webapps), "Security paranoid is not supported. Use strict, standard or off")
+}
+
+func TestStaticContents(t *testing.T) {
+	assert.Equal(t, []string{"app", "docs", "admin/build"}, staticContents(testWebApps()))
+	assert.Equal(t, []string{"build"}, staticContents(auroraApplication{Static: "build"}))
+}
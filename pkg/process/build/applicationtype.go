@@ -0,0 +1,75 @@
+package process
+
+import (
+	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"sort"
+)
+
+// MetadataLoader reads openshift.json of the deliverable, or DELIVERABLE_METADATA in the BuildConfig. It returns nil
+// when the metadata is only in Nexus, since the sidecar metadata is downloaded when the image is prepared
+type MetadataLoader func(cfg *config.Config, deliverable nexus.Deliverable) (interface{}, error)
+
+// MetadataValidator checks the metadata returned by the MetadataLoader of the same application type
+type MetadataValidator func(metadata interface{}) error
+
+// ApplicationTypeSpec is an application type. Each type registers its spec from its own package
+type ApplicationTypeSpec struct {
+	// How APPLICATION_TYPE, --type, PACKAGING and CLASSIFIER are read for the type
+	Config config.ApplicationTypeSpec
+	// Logged when the build starts, eg. Java
+	Name string
+	// The artifact downloader is for artifacts besides the deliverable, like the sidecar metadata
+	Prepper func(artifactDownloader nexus.Downloader) Prepper
+	// Optional. Reads the base image in the metadata of the deliverable
+	BaseImageResolver BaseImageResolver
+	// Optional. Reads the version in the deliverable or its metadata
+	VersionReader VersionReader
+	// Optional. Reads the metadata, so it is validated before the base image is pulled
+	MetadataLoader MetadataLoader
+	// Optional. Validates the metadata read by MetadataLoader
+	MetadataValidator MetadataValidator
+}
+
+var applicationTypes = make(map[config.ApplicationType]ApplicationTypeSpec)
+
+// RegisterApplicationType adds an application type, or replaces the type with the same name
+func RegisterApplicationType(spec ApplicationTypeSpec) {
+	config.RegisterApplicationType(spec.Config)
+	applicationTypes[spec.Config.Type] = spec
+}
+
+// FindApplicationType returns the registered application type
+func FindApplicationType(applicationType config.ApplicationType) (ApplicationTypeSpec, error) {
+	spec, exists := applicationTypes[applicationType]
+	if !exists {
+		return ApplicationTypeSpec{}, errors.Errorf("Application type %s cannot be built", applicationType)
+	}
+	return spec, nil
+}
+
+// SupportedApplicationTypes returns the names of the application types that can be built
+func SupportedApplicationTypes() []string {
+	supported := make([]string, 0, len(applicationTypes))
+	for _, spec := range applicationTypes {
+		supported = append(supported, spec.Config.Names[0])
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+// validateMetadata loads and validates the metadata, so a broken openshift.json fails the build early
+func validateMetadata(cfg *config.Config, deliverable nexus.Deliverable, applicationType ApplicationTypeSpec) error {
+	if applicationType.MetadataLoader == nil {
+		return nil
+	}
+	metadata, err := applicationType.MetadataLoader(cfg, deliverable)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read application metadata")
+	}
+	if metadata == nil || applicationType.MetadataValidator == nil {
+		return nil
+	}
+	return errors.Wrap(applicationType.MetadataValidator(metadata), "Invalid application metadata")
+}
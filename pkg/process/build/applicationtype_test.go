@@ -0,0 +1,64 @@
+package process
+
+import (
+	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type goMetadata struct {
+	Maintainer string
+}
+
+var goApplicationType = ApplicationTypeSpec{
+	Config: config.ApplicationTypeSpec{
+		Type:       "GoLeveranse",
+		Names:      []string{"go", "golang"},
+		Classifier: "Goleveransepakke",
+		Packaging:  config.TgzPackaging,
+		Archives:   []config.PackageType{config.TgzPackaging},
+	},
+	Name: "Go",
+	MetadataLoader: func(cfg *config.Config, deliverable nexus.Deliverable) (interface{}, error) {
+		if cfg.ApplicationSpec.DeliverableMetadata == "" {
+			return nil, nil
+		}
+		return &goMetadata{Maintainer: cfg.ApplicationSpec.DeliverableMetadata}, nil
+	},
+	MetadataValidator: func(metadata interface{}) error {
+		if metadata.(*goMetadata).Maintainer == "nobody" {
+			return errors.New("Deliverable metadata does not contain \"Docker.Maintainer\" element")
+		}
+		return nil
+	},
+}
+
+func TestRegisterApplicationType(t *testing.T) {
+	RegisterApplicationType(goApplicationType)
+
+	spec, err := FindApplicationType("GoLeveranse")
+	assert.NoError(t, err)
+	assert.Equal(t, "Go", spec.Name)
+	assert.Contains(t, SupportedApplicationTypes(), "go")
+
+	configSpec, err := config.FindApplicationType("golang")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Classifier("Goleveransepakke"), configSpec.DefaultClassifier(config.TgzPackaging))
+
+	_, err = FindApplicationType("CobolLeveranse")
+	assert.EqualError(t, err, "Application type CobolLeveranse cannot be built")
+}
+
+func TestValidateMetadata(t *testing.T) {
+	cfg := &config.Config{}
+	assert.NoError(t, validateMetadata(cfg, nexus.Deliverable{}, goApplicationType))
+
+	cfg.ApplicationSpec.DeliverableMetadata = "tester"
+	assert.NoError(t, validateMetadata(cfg, nexus.Deliverable{}, goApplicationType))
+
+	cfg.ApplicationSpec.DeliverableMetadata = "nobody"
+	assert.EqualError(t, validateMetadata(cfg, nexus.Deliverable{}, goApplicationType),
+		"Invalid application metadata: Deliverable metadata does not contain \"Docker.Maintainer\" element")
+}
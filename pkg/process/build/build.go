@@ -18,7 +18,9 @@ type Builder interface {
 	Pull(ctx context.Context, image runtime.DockerImage) error
 }
 
-func Build(ctx context.Context, credentials *docker.RegistryCredentials, provider docker.ImageInfoProvider, cfg *config.Config, downloader nexus.Downloader, prepper Prepper, builder Builder) error {
+// The prepper is created by the application type with the downloader for artifacts besides the deliverable
+func Build(ctx context.Context, credentials *docker.RegistryCredentials, provider docker.ImageInfoProvider, cfg *config.Config, downloader nexus.Downloader,
+	applicationType ApplicationTypeSpec, prepper Prepper, builder Builder) error {
 
 	logrus.Debugf("Download deliverable for GAV %-v", cfg.ApplicationSpec)
 	deliverable, err := downloader.DownloadArtifact(&cfg.ApplicationSpec.MavenGav, &cfg.NexusAccess)
@@ -26,10 +28,27 @@ func Build(ctx context.Context, credentials *docker.RegistryCredentials, provide
 		return errors.Wrapf(err, "Could not download deliverable %-v", cfg.ApplicationSpec)
 	}
 	application := cfg.ApplicationSpec
+
+	if err := validateMetadata(cfg, deliverable, applicationType); err != nil {
+		return err
+	}
+
+	if err := verifyDeliverableVersion(cfg, deliverable, applicationType.VersionReader); err != nil {
+		return err
+	}
+
+	baseImageSpec, baseImageSource := application.BaseImageSpec, config.BaseImageFromBuildConfig
+	if applicationType.BaseImageResolver != nil {
+		baseImageSpec, baseImageSource, err = applicationType.BaseImageResolver(cfg, deliverable)
+		if err != nil {
+			return errors.Wrap(err, "Unable to select base image")
+		}
+	}
+	logrus.Infof("Using base image %s:%s from the %s", baseImageSpec.BaseImage, baseImageSpec.BaseVersion, baseImageSource)
+
 	logrus.Debug("Extract build info")
 
-	imageInfo, err := provider.GetImageInfo(application.BaseImageSpec.BaseImage,
-		application.BaseImageSpec.BaseVersion)
+	imageInfo, err := provider.GetImageInfo(baseImageSpec.BaseImage, baseImageSpec.BaseVersion)
 	if err != nil {
 		return errors.Wrap(err, "Unable to get the complete build version")
 	}
@@ -39,10 +58,11 @@ func Build(ctx context.Context, credentials *docker.RegistryCredentials, provide
 	baseImage := runtime.BaseImage{
 		DockerImage: runtime.DockerImage{
 			Tag:        completeBaseImageVersion,
-			Repository: application.BaseImageSpec.BaseImage,
+			Repository: baseImageSpec.BaseImage,
 			Registry:   cfg.DockerSpec.GetInternalPullRegistryWithoutProtocol(),
 		},
 		ImageInfo: imageInfo,
+		Source:    baseImageSource,
 	}
 
 	buildImage := &runtime.ArchitectImage{
@@ -14,3 +14,15 @@ type Prepper func(
 	auroraVersion *runtime.AuroraVersion,
 	deliverable nexus.Deliverable,
 	baseImage runtime.BaseImage) ([]docker.DockerBuildConfig, error)
+
+// BaseImageResolver lets the deliverable choose the base image. It returns the chosen base image, and where it came from.
+// Builds without a resolver use the base image in the BuildConfig
+type BaseImageResolver func(
+	cfg *config.Config,
+	deliverable nexus.Deliverable) (config.DockerBaseImageSpec, string, error)
+
+// VersionReader reads the version embedded in the deliverable. It returns an empty version if the deliverable
+// does not tell its version
+type VersionReader func(
+	cfg *config.Config,
+	deliverable nexus.Deliverable) (string, error)
@@ -0,0 +1,38 @@
+package process
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/nexus"
+)
+
+// verifyDeliverableVersion compares the version in the deliverable with the version it is built as
+func verifyDeliverableVersion(cfg *config.Config, deliverable nexus.Deliverable, versionReader VersionReader) error {
+	if versionReader == nil || cfg.VersionCheck == config.VersionCheckOff {
+		return nil
+	}
+	deliverableVersion, err := versionReader(cfg, deliverable)
+	if err != nil {
+		if cfg.VersionCheck == config.VersionCheckFail {
+			return errors.Wrap(err, "Unable to read the version of the deliverable")
+		}
+		logrus.Warnf("Unable to read the version of the deliverable: %s", err)
+		return nil
+	}
+	if deliverableVersion == "" {
+		logrus.Debug("The deliverable does not tell its version")
+		return nil
+	}
+
+	gav := cfg.ApplicationSpec.MavenGav
+	if gav.MatchesVersion(deliverableVersion) {
+		return nil
+	}
+	message := "The deliverable has version %s, but is built as version %s"
+	if cfg.VersionCheck == config.VersionCheckFail {
+		return errors.Errorf(message+". Set VERSION_CHECK to warn to build it anyway", deliverableVersion, gav.Version)
+	}
+	logrus.Warnf(message, deliverableVersion, gav.Version)
+	return nil
+}
@@ -0,0 +1,35 @@
+package process
+
+import (
+	"github.com/pkg/errors"
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVerifyDeliverableVersionWhenTheVersionCanNotBeRead(t *testing.T) {
+	failingReader := func(*config.Config, nexus.Deliverable) (string, error) {
+		return "", errors.New("Could not find metadata/openshift.json")
+	}
+	cfg := &config.Config{VersionCheck: config.VersionCheckWarn}
+	assert.NoError(t, verifyDeliverableVersion(cfg, nexus.Deliverable{}, failingReader))
+
+	cfg.VersionCheck = config.VersionCheckFail
+	assert.EqualError(t, verifyDeliverableVersion(cfg, nexus.Deliverable{}, failingReader),
+		"Unable to read the version of the deliverable: Could not find metadata/openshift.json")
+}
+
+func TestVerifyDeliverableVersion(t *testing.T) {
+	reader := func(*config.Config, nexus.Deliverable) (string, error) {
+		return "1.2.3-20200131.120000-4", nil
+	}
+	cfg := &config.Config{VersionCheck: config.VersionCheckFail}
+	cfg.ApplicationSpec.MavenGav.Version = "1.2.3-SNAPSHOT"
+	assert.NoError(t, verifyDeliverableVersion(cfg, nexus.Deliverable{}, reader))
+
+	cfg.ApplicationSpec.MavenGav.Version = "1.2.4"
+	assert.Error(t, verifyDeliverableVersion(cfg, nexus.Deliverable{}, reader))
+	cfg.VersionCheck = config.VersionCheckWarn
+	assert.NoError(t, verifyDeliverableVersion(cfg, nexus.Deliverable{}, reader))
+}
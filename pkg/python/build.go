@@ -0,0 +1,114 @@
+package python
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/skatteetaten/architect/pkg/docker"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/skatteetaten/architect/pkg/process/build"
+	pythonconfig "github.com/skatteetaten/architect/pkg/python/config"
+	"github.com/skatteetaten/architect/pkg/python/prepare"
+	"github.com/skatteetaten/architect/pkg/util"
+	"strings"
+)
+
+func init() {
+	process.RegisterApplicationType(process.ApplicationTypeSpec{
+		Config: config.ApplicationTypeSpec{
+			Type:           config.PythonLeveranse,
+			Names:          []string{"python"},
+			Classifier:     config.Pythonleveransepakke,
+			Packaging:      config.ZipPackaging,
+			Archives:       []config.PackageType{config.ZipPackaging},
+			ParsePackaging: parsePackaging,
+		},
+		Name:              "Python",
+		Prepper:           Prepper,
+		VersionReader:     VersionReader(),
+		MetadataLoader:    MetadataLoader(),
+		MetadataValidator: MetadataValidator(),
+	})
+}
+
+// PACKAGING is zip for a Pythonleveransepakke, or whl for a wheel
+func parsePackaging(value string) (config.PackageType, error) {
+	packaging := config.PackageType(strings.ToLower(value))
+	if packaging != config.ZipPackaging && packaging != config.WhlPackaging {
+		return "", errors.Errorf("Illegal value %s of PACKAGING. Use zip or whl", value)
+	}
+	return packaging, nil
+}
+
+// The downloader is used for the sidecar metadata of a wheel
+func Prepper(artifactDownloader nexus.Downloader) process.Prepper {
+	return func(cfg *config.Config, auroraVersion *runtime.AuroraVersion, deliverable nexus.Deliverable,
+		baseImage runtime.BaseImage) ([]docker.DockerBuildConfig, error) {
+
+		logrus.Debug("Prepare output image")
+		buildPath, err := prepare.Prepare(*cfg, auroraVersion, deliverable, baseImage,
+			nexus.NewArtifactDownloader(artifactDownloader, &cfg.NexusAccess))
+
+		if err != nil {
+			return nil, errors.Wrap(err, "Error prepare artifact")
+		}
+
+		buildConf := docker.DockerBuildConfig{
+			AuroraVersion:    auroraVersion,
+			BuildFolder:      buildPath,
+			DockerRepository: cfg.DockerSpec.OutputRepository,
+			Baseimage:        baseImage.DockerImage,
+		}
+		return []docker.DockerBuildConfig{buildConf}, nil
+	}
+}
+
+// VersionReader reads the version in the root folder of a zip. The version of a wheel follows PEP 440, and is not
+// compared with the Maven version
+func VersionReader() process.VersionReader {
+	return func(cfg *config.Config, deliverable nexus.Deliverable) (string, error) {
+		if cfg.ApplicationSpec.MavenGav.Type == config.WhlPackaging {
+			return "", nil
+		}
+		rootFolder, err := util.DeliverableRootFolder(deliverable.Path)
+		if err != nil {
+			return "", err
+		}
+		return util.DeliverableVersion(rootFolder, cfg.ApplicationSpec.MavenGav.ArtifactId), nil
+	}
+}
+
+// MetadataLoader reads the metadata in a zip, and falls back to the metadata in the BuildConfig like a wheel
+func MetadataLoader() process.MetadataLoader {
+	return func(cfg *config.Config, deliverable nexus.Deliverable) (interface{}, error) {
+		var content []byte
+		if cfg.ApplicationSpec.MavenGav.Type != config.WhlPackaging {
+			inDeliverable, found, err := util.FindFileInDeliverable(deliverable.Path, util.DeliveryMetadataPath)
+			if err != nil {
+				return nil, err
+			} else if found {
+				content = inDeliverable
+			}
+		}
+		if content == nil && cfg.ApplicationSpec.DeliverableMetadata != "" {
+			content = []byte(cfg.ApplicationSpec.DeliverableMetadata)
+		}
+		if content == nil {
+			return nil, nil
+		}
+		return pythonconfig.NewDeliverableMetadata(bytes.NewReader(content))
+	}
+}
+
+// MetadataValidator checks the metadata with the same rules as when the Dockerfile is written
+func MetadataValidator() process.MetadataValidator {
+	return func(metadata interface{}) error {
+		meta, ok := metadata.(*pythonconfig.DeliverableMetadata)
+		if !ok {
+			return errors.Errorf("Unexpected python metadata %T", metadata)
+		}
+		return prepare.VerifyMetadata(*meta)
+	}
+}
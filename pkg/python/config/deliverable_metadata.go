@@ -0,0 +1,37 @@
+package config
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	doozer "github.com/skatteetaten/architect/pkg/doozer/config"
+	"io"
+	"io/ioutil"
+)
+
+// The docker and openshift elements are the same as in the doozer metadata
+type DeliverableMetadata struct {
+	Docker    *doozer.MetadataDocker    `json:"docker"`
+	Python    *MetadataPython           `json:"python"`
+	Openshift *doozer.MetadataOpenShift `json:"openshift"`
+}
+
+type MetadataPython struct {
+	EntryModule  string   `json:"entryModule"`  // The module run with python -m, eg. myservice.main
+	Args         []string `json:"args"`         // Optional. Arguments to the entry module
+	Requirements string   `json:"requirements"` // Optional. Relative to the application. Defaults to requirements.txt if it exists
+}
+
+func NewDeliverableMetadata(reader io.Reader) (*DeliverableMetadata, error) {
+	var meta DeliverableMetadata
+	content, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(content, &meta); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal json metadata")
+	}
+
+	return &meta, nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestNewFromJson(t *testing.T) {
+	const openshiftJson string = `{
+  "docker": {
+    "maintainer": "Aurora OpenShift Utvikling <utvpaas@skatteetaten.no>",
+    "labels": {
+      "io.k8s.description": "Demo application with python on Openshift."
+    }
+  },
+  "python": {
+    "entryModule": "demo.main",
+    "args": ["--port", "8080"],
+    "requirements": "requirements/prod.txt"
+  },
+  "openshift" : {
+      "readinessUrl": "/health"
+  }
+}`
+
+	meta, err := NewDeliverableMetadata(strings.NewReader(openshiftJson))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Aurora OpenShift Utvikling <utvpaas@skatteetaten.no>", meta.Docker.Maintainer)
+	assert.Equal(t, "Demo application with python on Openshift.", meta.Docker.Labels["io.k8s.description"])
+	assert.Equal(t, "demo.main", meta.Python.EntryModule)
+	assert.Equal(t, []string{"--port", "8080"}, meta.Python.Args)
+	assert.Equal(t, "requirements/prod.txt", meta.Python.Requirements)
+	assert.Equal(t, "/health", meta.Openshift.ReadinessURL)
+}
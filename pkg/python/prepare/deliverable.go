@@ -0,0 +1,56 @@
+package prepare
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	"github.com/skatteetaten/architect/pkg/util"
+	"path/filepath"
+)
+
+/*
+A python deliverable is a zip with the application, or a wheel. It is put in the application folder:
+
+	zip    the content of the archive, without its root folder
+	wheel  the wheel with its own file name, eg. myservice-1.0.0-py3-none-any.whl
+
+The metadata is metadata/openshift.json in the zip. Without it, DELIVERABLE_METADATA in the BuildConfig or the
+openshift.json deployed with classifier openshift next to the deliverable in Nexus is used.
+
+prepareDeliverable returns the file name of the wheel, or an empty string for a zip.
+*/
+func prepareDeliverable(cfg config.Config, deliverable nexus.Deliverable, dockerBuildPath string, downloadArtifact nexus.ArtifactDownloader) (string, error) {
+	applicationFolder := filepath.Join(dockerBuildPath, util.ApplicationBuildFolder)
+
+	var wheel string
+	if cfg.ApplicationSpec.MavenGav.Type == config.WhlPackaging {
+		var err error
+		wheel, err = wheelFileName(deliverable.Path)
+		if err != nil {
+			return "", err
+		}
+		logrus.Infof("Using the wheel deliverable as %s", wheel)
+		if err := util.CopyFile(deliverable.Path, filepath.Join(applicationFolder, wheel)); err != nil {
+			return "", errors.Wrap(err, "Failed to copy the deliverable")
+		}
+	} else if err := util.ExtractAndRenameDeliverable(dockerBuildPath, deliverable.Path); err != nil {
+		return "", errors.Wrap(err, "Failed to extract application archive")
+	}
+
+	metadataExists, err := util.Exists(filepath.Join(applicationFolder, util.DeliveryMetadataPath))
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to read application metadata")
+	} else if metadataExists {
+		return wheel, nil
+	}
+
+	metadata, err := nexus.FindSidecarMetadata(cfg, downloadArtifact)
+	if err != nil {
+		return "", err
+	}
+	if err := util.NewFileWriter(applicationFolder)(util.NewByteWriter(metadata), util.DeliveryMetadataPath); err != nil {
+		return "", errors.Wrap(err, "Failed to write application metadata")
+	}
+	return wheel, nil
+}
@@ -0,0 +1,104 @@
+package prepare
+
+import (
+	"github.com/pkg/errors"
+	global "github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/skatteetaten/architect/pkg/docker"
+	doozerconfig "github.com/skatteetaten/architect/pkg/doozer/config"
+	"github.com/skatteetaten/architect/pkg/python/config"
+	"github.com/skatteetaten/architect/pkg/templates"
+	"github.com/skatteetaten/architect/pkg/util"
+	"io"
+	"regexp"
+)
+
+type DockerfileData struct {
+	BaseImage   string
+	Home        string
+	Maintainer  string
+	Labels      map[string]string
+	Env         map[string]string
+	HealthCheck *docker.HealthCheck
+	Extensions  string
+	VirtualEnv  string
+	// The requirements file installed in the virtualenv, relative to the application. Empty if there is none
+	Requirements string
+	// The wheel installed in the virtualenv. Empty for a zip
+	Wheel string
+	// The PyPI index pip installs from. Empty for the default index
+	IndexURL string
+}
+
+// The locale of a python image when neither the deliverable nor the base image has one
+const DefaultLocale = "en_US.UTF-8"
+
+var entryModule = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// VerifyMetadata is also the metadata validator of the python application type
+func VerifyMetadata(meta config.DeliverableMetadata) error {
+	if err := doozerconfig.VerifyDocker(meta.Docker); err != nil {
+		return err
+	}
+	if meta.Python == nil {
+		return errors.Errorf("Deliverable metadata does not contain \"Python\" element")
+	} else if !entryModule.MatchString(meta.Python.EntryModule) {
+		return errors.Errorf("Illegal entryModule %q in python metadata. Use a module name, eg. myservice.main", meta.Python.EntryModule)
+	}
+
+	return nil
+}
+
+// The virtualenv is activated with VIRTUAL_ENV and PATH
+func virtualEnvVariables(virtualEnv string) map[string]string {
+	return map[string]string{
+		"VIRTUAL_ENV": virtualEnv,
+		"PATH":        virtualEnv + "/bin:$PATH",
+	}
+}
+
+// home is HOME in the base image. requirements and wheel are relative to the application. indexURL is the PyPI index in Nexus
+func NewDockerFile(dockerSpec global.DockerSpec, auroraVersion runtime.AuroraVersion, meta config.DeliverableMetadata,
+	baseImage runtime.DockerImage, imageBuildTime string, home string, requirements string, wheel string, indexURL string) util.WriterFunc {
+	return func(writer io.Writer) error {
+		if err := VerifyMetadata(meta); err != nil {
+			return err
+		}
+		env, err := meta.Docker.ImageEnv(auroraVersion, dockerSpec.PushExtraTags, imageBuildTime, meta.Openshift, DefaultLocale,
+			virtualEnvVariables(virtualEnv(home)))
+		if err != nil {
+			return err
+		}
+		healthCheck, err := meta.Openshift.HealthCheck(dockerSpec.HealthCheckTool)
+		if err != nil {
+			return err
+		}
+		extensions, err := docker.NewDockerExtensions(meta.Docker.Extensions)
+		if err != nil {
+			return err
+		}
+		labels := meta.Docker.ImageLabels(meta.Openshift)
+		extensions.AddLabels(labels)
+
+		dockerFileTemplate, err := dockerSpec.Templates.Get(templates.PythonDockerfile)
+		if err != nil {
+			return err
+		}
+
+		data := &DockerfileData{
+			BaseImage:    baseImage.GetCompleteDockerTagName(),
+			Home:         home,
+			Maintainer:   meta.Docker.Maintainer,
+			Labels:       labels,
+			Env:          env,
+			HealthCheck:  healthCheck,
+			Extensions:   extensions.Instructions(),
+			VirtualEnv:   virtualEnv(home),
+			Requirements: requirements,
+			Wheel:        wheel,
+			IndexURL:     indexURL,
+		}
+
+		return util.NewTemplateWriter(data, "Dockerfile", dockerFileTemplate)(writer)
+	}
+}
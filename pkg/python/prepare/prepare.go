@@ -0,0 +1,169 @@
+package prepare
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/skatteetaten/architect/pkg/config"
+	"github.com/skatteetaten/architect/pkg/config/runtime"
+	"github.com/skatteetaten/architect/pkg/docker"
+	"github.com/skatteetaten/architect/pkg/nexus"
+	deliverable "github.com/skatteetaten/architect/pkg/python/config"
+	"github.com/skatteetaten/architect/pkg/templates"
+	"github.com/skatteetaten/architect/pkg/util"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// The image architecture label of the python base images
+const ImageArchitecture = "python"
+
+// The requirements file installed when the metadata has none
+const DefaultRequirements = "requirements.txt"
+
+func Prepare(cfg config.Config, auroraVersions *runtime.AuroraVersion, deliverable nexus.Deliverable, baseImage runtime.BaseImage,
+	downloadArtifact nexus.ArtifactDownloader) (string, error) {
+
+	if architecture := baseImage.GetImageArchitecture(); architecture != ImageArchitecture {
+		return "", runtime.UnsupportedImageArchitectureError(architecture, []string{ImageArchitecture})
+	}
+
+	dockerSpec := cfg.DockerSpec
+
+	// Create docker build folder
+	dockerBuildPath, err := ioutil.TempDir("", "deliverable")
+
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create root folder of Docker context")
+	}
+
+	// Extract or copy deliverable
+	applicationFolder := filepath.Join(dockerBuildPath, util.ApplicationBuildFolder)
+	wheel, err := prepareDeliverable(cfg, deliverable, dockerBuildPath, downloadArtifact)
+
+	if err != nil {
+		return "", err
+	}
+
+	// Load metadata
+	meta, err := loadDeliverableMetadata(filepath.Join(applicationFolder, util.DeliveryMetadataPath))
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to read application metadata")
+	}
+	if err := VerifyMetadata(*meta); err != nil {
+		return "", err
+	}
+
+	requirements, err := findRequirements(meta.Python, applicationFolder)
+	if err != nil {
+		return "", err
+	}
+
+	indexURL, err := pypiIndex(cfg.NexusAccess.PypiUrl)
+	if err != nil {
+		return "", err
+	}
+
+	extensions, err := docker.NewDockerExtensions(meta.Docker.Extensions)
+	if err != nil {
+		return "", err
+	}
+	if err := extensions.CopyFiles(applicationFolder, dockerBuildPath); err != nil {
+		return "", err
+	}
+
+	dockerSpec.Templates, err = templates.Load(dockerSpec.TemplateDir, baseImage.GetTemplateVersion())
+
+	if err != nil {
+		return "", err
+	}
+	if dockerSpec.Templates.Legacy() {
+		return "", templates.LegacyError("Python")
+	}
+	dockerSpec.HealthCheckTool = baseImage.GetHealthCheckTool()
+
+	home := baseImage.ImageInfo.Enviroment[docker.ENV_HOME]
+	if home == "" {
+		home = util.DockerBasedir
+	}
+
+	fileWriter := util.NewFileWriter(dockerBuildPath)
+
+	logrus.Info("Running radish build (python)")
+	if err := fileWriter(newRadishDescriptor(meta, path.Join(home, util.ApplicationFolder), virtualEnv(home)), "radish.json"); err != nil {
+		return "", errors.Wrap(err, "Unable to create radish descriptor")
+	}
+
+	if meta.Docker != nil {
+		meta.Docker.AddBaseImageDefaults(baseImage)
+	}
+
+	if err = fileWriter(NewDockerFile(dockerSpec, *auroraVersions, *meta, baseImage.DockerImage, docker.GetUtcTimestamp(), home, requirements, wheel, indexURL),
+		"Dockerfile"); err != nil {
+		return "", errors.Wrap(err, "Failed to create Dockerfile")
+	}
+
+	return dockerBuildPath, nil
+}
+
+func virtualEnv(home string) string {
+	return path.Join(home, "venv")
+}
+
+// findRequirements checks that the requirements file in the metadata exists. Without one, requirements.txt is used if
+// the application has it
+func findRequirements(python *deliverable.MetadataPython, applicationFolder string) (string, error) {
+	requirements := python.Requirements
+	if requirements == "" {
+		requirements = DefaultRequirements
+	} else if strings.HasPrefix(requirements, "/") || path.Clean(requirements) != requirements ||
+		strings.HasPrefix(requirements, "..") || strings.ContainsAny(requirements, " \t\r\n\"'\\$") {
+		return "", errors.Errorf("Illegal requirements %s in python metadata. Use a path relative to the application", requirements)
+	}
+
+	exists, err := util.Exists(filepath.Join(applicationFolder, filepath.FromSlash(requirements)))
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to read %s in the deliverable", requirements)
+	} else if exists {
+		return requirements, nil
+	} else if python.Requirements != "" {
+		return "", errors.Errorf("requirements %s in python metadata is not in the deliverable", requirements)
+	}
+	return "", nil
+}
+
+// pypiIndex checks the PyPI index in the Nexus configuration. It is written to the Dockerfile, so it cannot have
+// credentials or characters that end the pip command
+func pypiIndex(pypiUrl string) (string, error) {
+	if pypiUrl == "" {
+		return "", nil
+	}
+	parsed, err := url.Parse(pypiUrl)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.User != nil ||
+		strings.ContainsAny(pypiUrl, " \t\r\n\"'\\$`;&|") {
+		return "", errors.Errorf("Illegal pypiUrl %s in the Nexus configuration. Use a http or https url without credentials", pypiUrl)
+	}
+	return pypiUrl, nil
+}
+
+func loadDeliverableMetadata(metafile string) (*deliverable.DeliverableMetadata, error) {
+	reader, err := os.Open(metafile)
+
+	if os.IsNotExist(err) {
+		return nil, errors.Errorf("Could not find %s in deliverable", path.Base(metafile))
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Failed to open application metadata file")
+	}
+	defer reader.Close()
+
+	deliverableMetadata, err := deliverable.NewDeliverableMetadata(reader)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to load metadata from %s", path.Base(metafile))
+	}
+
+	return deliverableMetadata, nil
+}
@@ -0,0 +1,48 @@
+package prepare
+
+import (
+	"encoding/json"
+	"github.com/skatteetaten/architect/pkg/python/config"
+	"github.com/skatteetaten/architect/pkg/util"
+	"io"
+)
+
+type Type struct {
+	Type    string `json:"Type"`
+	Version string `json:"Version"`
+}
+
+type PythonDescriptorData struct {
+	Basedir     string   `json:"Basedir"`
+	VirtualEnv  string   `json:"VirtualEnv"`
+	EntryModule string   `json:"EntryModule"`
+	Args        []string `json:"Args"`
+}
+
+type PythonDescriptor struct {
+	Type
+	Data PythonDescriptorData
+}
+
+// The base image runs <virtualEnv>/bin/python -m <entryModule> <args> in basedir
+func newRadishDescriptor(meta *config.DeliverableMetadata, basedir string, virtualEnv string) util.WriterFunc {
+	return func(writer io.Writer) error {
+		args := meta.Python.Args
+		if args == nil {
+			args = []string{}
+		}
+		desc := PythonDescriptor{
+			Type: Type{
+				Type:    "Python",
+				Version: "1",
+			},
+			Data: PythonDescriptorData{
+				Basedir:     basedir,
+				VirtualEnv:  virtualEnv,
+				EntryModule: meta.Python.EntryModule,
+				Args:        args,
+			},
+		}
+		return json.NewEncoder(writer).Encode(desc)
+	}
+}
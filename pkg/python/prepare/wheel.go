@@ -0,0 +1,68 @@
+package prepare
+
+import (
+	"archive/zip"
+	"bufio"
+	"github.com/pkg/errors"
+	"path"
+	"strings"
+)
+
+// pip only installs a wheel with a file name following the wheel naming convention,
+// <distribution>-<version>-<python tag>-<abi tag>-<platform tag>.whl. The deliverable has the Maven file name, eg.
+// <artifactId>-<version>.whl, so the name is restored from the .dist-info folder and the WHEEL file in the wheel
+func wheelFileName(wheelPath string) (string, error) {
+	zipReader, err := zip.OpenReader(wheelPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to open wheel %s", wheelPath)
+	}
+	defer zipReader.Close()
+
+	for _, zipEntry := range zipReader.File {
+		distInfo, name := path.Split(zipEntry.Name)
+		if name != "WHEEL" || !strings.HasSuffix(distInfo, ".dist-info/") || strings.Count(distInfo, "/") != 1 {
+			continue
+		}
+		reader, err := zipEntry.Open()
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed to open %s in wheel", zipEntry.Name)
+		}
+		defer reader.Close()
+
+		var pythonTags, abiTags, platformTags []string
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "Tag:") {
+				continue
+			}
+			tag := strings.Split(strings.TrimSpace(strings.TrimPrefix(line, "Tag:")), "-")
+			if len(tag) != 3 {
+				return "", errors.Errorf("Illegal tag %s in %s", line, zipEntry.Name)
+			}
+			pythonTags = appendUnique(pythonTags, tag[0])
+			abiTags = appendUnique(abiTags, tag[1])
+			platformTags = appendUnique(platformTags, tag[2])
+		}
+		if err := scanner.Err(); err != nil {
+			return "", errors.Wrapf(err, "Failed to read %s in wheel", zipEntry.Name)
+		}
+		if len(pythonTags) == 0 {
+			return "", errors.Errorf("No tags in %s", zipEntry.Name)
+		}
+
+		nameAndVersion := strings.TrimSuffix(distInfo, ".dist-info/")
+		return strings.Join([]string{nameAndVersion, strings.Join(pythonTags, "."), strings.Join(abiTags, "."),
+			strings.Join(platformTags, ".")}, "-") + ".whl", nil
+	}
+	return "", errors.Errorf("Could not find the .dist-info folder in wheel %s", wheelPath)
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
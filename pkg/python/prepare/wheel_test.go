@@ -0,0 +1,49 @@
+package prepare
+
+import (
+	"archive/zip"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWheelFileName(t *testing.T) {
+	wheel := createWheel(t, "Tag: py2-none-any\nTag: py3-none-any\n")
+	defer os.RemoveAll(filepath.Dir(wheel))
+
+	fileName, err := wheelFileName(wheel)
+	assert.NoError(t, err)
+	assert.Equal(t, "demo-1.0.0-py2.py3-none-any.whl", fileName)
+}
+
+func TestWheelFileNameWithoutTags(t *testing.T) {
+	wheel := createWheel(t, "Wheel-Version: 1.0\n")
+	defer os.RemoveAll(filepath.Dir(wheel))
+
+	_, err := wheelFileName(wheel)
+	assert.Error(t, err)
+}
+
+func createWheel(t *testing.T, wheelFile string) string {
+	folder, err := ioutil.TempDir("", "wheel")
+	assert.NoError(t, err)
+	wheel := filepath.Join(folder, "demo-1.0.0.whl")
+	file, err := os.Create(wheel)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	for name, content := range map[string]string{
+		"demo/__init__.py":           "",
+		"demo-1.0.0.dist-info/WHEEL": "Wheel-Version: 1.0\nRoot-Is-Purelib: true\n" + wheelFile,
+	} {
+		writer, err := zipWriter.Create(name)
+		assert.NoError(t, err)
+		_, err = writer.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zipWriter.Close())
+	return wheel
+}
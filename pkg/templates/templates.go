@@ -0,0 +1,122 @@
+package templates
+
+import (
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The names of the templates in a template set. In a template directory they are the paths of the template files
+const (
+	JavaDockerfile          = "java/Dockerfile"
+	JavaTestImageDockerfile = "java/Dockerfile-test"
+	NodejsDockerfile        = "nodejs/Dockerfile"
+	NodejsLegacyDockerfile  = "nodejs/Dockerfile-legacy"
+	NodejsLegacyNginxConf   = "nodejs/nginx.conf"
+	DoozerDockerfile        = "doozer/Dockerfile"
+	DoozerDockerfileCmd     = "doozer/Dockerfile-cmd"
+	PythonDockerfile        = "python/Dockerfile"
+)
+
+// The template version of base images without the template version label. It is the legacy version, see version1
+const DefaultVersion = "1"
+
+var names = []string{JavaDockerfile, JavaTestImageDockerfile, NodejsDockerfile, NodejsLegacyDockerfile,
+	NodejsLegacyNginxConf, DoozerDockerfile, DoozerDockerfileCmd, PythonDockerfile}
+
+// The template sets compiled into Architect
+var embeddedTemplateSets = map[string]map[string]string{
+	"1": version1,
+	"2": version2,
+}
+
+var templateVersion = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+/*
+TemplateSet is the Dockerfile and nginx templates for one template version. Base images tell which template version
+they support with the template version label, so a template change can be rolled out one base image at a time.
+
+Operators can override the embedded templates with a template directory, with one folder per version:
+
+	<templateDir>/2/java/Dockerfile
+	<templateDir>/2/nodejs/nginx.conf
+
+The templates in the directory have precedence. A version that is only in the directory is allowed.
+*/
+type TemplateSet struct {
+	Version   string
+	templates map[string]string
+}
+
+// Load finds the template set for the version. An empty version is the default version
+func Load(templateDir string, version string) (*TemplateSet, error) {
+	if version == "" {
+		version = DefaultVersion
+	}
+	if !templateVersion.MatchString(version) {
+		return nil, errors.Errorf("Illegal template version %s", version)
+	}
+
+	templateSet := &TemplateSet{Version: version, templates: make(map[string]string)}
+	embedded, found := embeddedTemplateSets[version]
+	for name, template := range embedded {
+		templateSet.templates[name] = template
+	}
+
+	if templateDir != "" {
+		for _, name := range names {
+			content, err := ioutil.ReadFile(filepath.Join(templateDir, version, filepath.FromSlash(name)))
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return nil, errors.Wrapf(err, "Could not read template %s", name)
+			}
+			templateSet.templates[name] = string(content)
+			found = true
+		}
+	}
+
+	if !found {
+		return nil, errors.Errorf("Template version %s of the base image is not supported. Supported versions are %s",
+			version, strings.Join(SupportedVersions(), ", "))
+	}
+	return templateSet, nil
+}
+
+// SupportedVersions returns the versions of the embedded template sets
+func SupportedVersions() []string {
+	versions := make([]string, 0, len(embeddedTemplateSets))
+	for version := range embeddedTemplateSets {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Legacy tells if the templates are the default version. A nil template set is the default version. The preppers
+// keep the build context of the legacy templates as it was before template sets were introduced
+func (m *TemplateSet) Legacy() bool {
+	return m == nil || m.Version == DefaultVersion
+}
+
+// LegacyError is the error for a feature the legacy templates cannot render
+func LegacyError(feature string) error {
+	return errors.Errorf("%s is not supported by template version %s. Use a base image with template version 2",
+		feature, DefaultVersion)
+}
+
+// Get returns the named template. A nil template set is the embedded set of the default version
+func (m *TemplateSet) Get(name string) (string, error) {
+	if m == nil {
+		m = &TemplateSet{Version: DefaultVersion, templates: embeddedTemplateSets[DefaultVersion]}
+	}
+	template, exists := m.templates[name]
+	if !exists {
+		return "", errors.Errorf("Template %s is missing in template version %s", name, m.Version)
+	}
+	return template, nil
+}
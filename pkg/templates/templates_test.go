@@ -0,0 +1,79 @@
+package templates_test
+
+import (
+	"github.com/skatteetaten/architect/pkg/templates"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEmbeddedTemplates(t *testing.T) {
+	templateSet, err := templates.Load("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, templates.DefaultVersion, templateSet.Version)
+
+	dockerfile, err := templateSet.Get(templates.JavaDockerfile)
+	assert.NoError(t, err)
+	assert.Contains(t, dockerfile, "FROM {{.BaseImage}}")
+
+	var defaultSet *templates.TemplateSet
+	defaultDockerfile, err := defaultSet.Get(templates.JavaDockerfile)
+	assert.NoError(t, err)
+	assert.Equal(t, dockerfile, defaultDockerfile)
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	_, err := templates.Load("", "42")
+	assert.EqualError(t, err, "Template version 42 of the base image is not supported. Supported versions are 1, 2")
+
+	_, err = templates.Load("", "../1")
+	assert.EqualError(t, err, "Illegal template version ../1")
+}
+
+func TestLoadTemplatesFromTemplateDir(t *testing.T) {
+	templateDir, err := ioutil.TempDir("", "templates")
+	assert.NoError(t, err)
+	defer os.RemoveAll(templateDir)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(templateDir, "1", "java"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(templateDir, "1", "java", "Dockerfile"), []byte("FROM override"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(templateDir, "3", "nodejs"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(templateDir, "3", "nodejs", "Dockerfile"), []byte("FROM v3"), 0644))
+
+	templateSet, err := templates.Load(templateDir, "1")
+	assert.NoError(t, err)
+	dockerfile, err := templateSet.Get(templates.JavaDockerfile)
+	assert.NoError(t, err)
+	assert.Equal(t, "FROM override", dockerfile)
+	dockerfile, err = templateSet.Get(templates.NodejsDockerfile)
+	assert.NoError(t, err)
+	assert.Contains(t, dockerfile, "FROM {{.Baseimage}}")
+
+	templateSet, err = templates.Load(templateDir, "3")
+	assert.NoError(t, err)
+	dockerfile, err = templateSet.Get(templates.NodejsDockerfile)
+	assert.NoError(t, err)
+	assert.Equal(t, "FROM v3", dockerfile)
+	_, err = templateSet.Get(templates.JavaDockerfile)
+	assert.EqualError(t, err, "Template java/Dockerfile is missing in template version 3")
+}
+
+func TestLegacyTemplates(t *testing.T) {
+	var defaultSet *templates.TemplateSet
+	assert.True(t, defaultSet.Legacy())
+
+	templateSet, err := templates.Load("", "")
+	assert.NoError(t, err)
+	assert.True(t, templateSet.Legacy())
+	_, err = templateSet.Get(templates.PythonDockerfile)
+	assert.EqualError(t, err, "Template python/Dockerfile is missing in template version 1")
+
+	templateSet, err = templates.Load("", "2")
+	assert.NoError(t, err)
+	assert.False(t, templateSet.Legacy())
+	dockerfile, err := templateSet.Get(templates.PythonDockerfile)
+	assert.NoError(t, err)
+	assert.Contains(t, dockerfile, "python3 -m venv")
+}
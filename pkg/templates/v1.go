@@ -0,0 +1,151 @@
+package templates
+
+// Version 1 is the templates used before template sets were introduced, kept as they were. Base images without a
+// template version label are built with it, so they get the same images as before
+var version1 = map[string]string{
+	JavaDockerfile: `FROM {{.BaseImage}}
+
+MAINTAINER {{.Maintainer}}
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+COPY ./app radish.json $HOME/
+RUN find $HOME/application -type d -exec chmod 755 {} + && \
+	find $HOME/application -type f -exec chmod 644 {} + && \
+	mkdir -p $HOME/logs && \
+	chmod 777 $HOME/logs && \
+	ln -s $HOME/logs $HOME/application/logs
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+`,
+
+	JavaTestImageDockerfile: `FROM {{.BaseImage}}
+
+MAINTAINER {{.Maintainer}}
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+COPY ./app radish.json $HOME/
+RUN find $HOME/application -type d -exec chmod 777 {} + && \
+	find $HOME/application -type f -exec chmod 644 {} + && \
+	mkdir -p $HOME/logs && \
+	chmod 777 $HOME/logs && \
+	ln -s $HOME/logs $HOME/application/logs
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+`,
+
+	NodejsDockerfile: `FROM {{.Baseimage}}
+
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+COPY ./{{.PackageDirectory}} /u01/application
+
+COPY ./overrides /u01/bin/
+
+COPY nginx-radish.json $HOME/
+
+COPY ./{{.PackageDirectory}}/{{.Static}} /u01/static{{.Path}}
+
+RUN chmod 666 /etc/nginx/nginx.conf && \
+    chmod 777 /etc/nginx && \
+    chmod 755 /u01/bin/*
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+
+WORKDIR "/u01/"
+
+CMD ["/u01/bin/run_nginx"]`,
+
+	NodejsLegacyDockerfile: `FROM {{.Baseimage}}
+
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+COPY ./{{.PackageDirectory}} /u01/application
+
+COPY ./overrides /u01/bin/
+
+COPY ./{{.PackageDirectory}}/{{.Static}} /u01/static{{.Path}}
+
+COPY nginx.conf /etc/nginx/nginx.conf
+
+RUN chmod 666 /etc/nginx/nginx.conf && \
+    chmod 777 /etc/nginx && \
+    chmod 755 /u01/bin/*
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+
+WORKDIR "/u01/"
+
+CMD ["/u01/bin/run_nginx"]`,
+
+	NodejsLegacyNginxConf: `
+worker_processes  1;
+error_log stderr;
+
+events {
+    worker_connections  1024;
+}
+
+
+http {
+    include       /etc/nginx/mime.types;
+    default_type  application/octet-stream;
+
+    log_format  main  '$remote_addr - $remote_user [$time_local] "$request" '
+                      '$status $body_bytes_sent "$http_referer" '
+                      '"$http_user_agent" "$http_x_forwarded_for"';
+
+    access_log  /dev/stdout;
+
+    sendfile        on;
+    #tcp_nopush     on;
+
+    keepalive_timeout  65;
+
+    #gzip  on;
+
+    index index.html;
+
+    server {
+       listen 8080;
+
+       location /api {
+          {{if or .HasNodeJSApplication .ConfigurableProxy}}proxy_pass http://${PROXY_PASS_HOST}:${PROXY_PASS_PORT};{{else}}return 404;{{end}}{{range $key, $value := .NginxOverrides}}
+          {{$key}} {{$value}};{{end}}
+       }
+{{if .SPA}}
+       location {{.Path}} {
+          root /u01/static;
+          try_files $uri {{.Path}}index.html;{{else}}
+       location {{.Path}} {
+          root /u01/static;{{end}}{{range $key, $value := .ExtraStaticHeaders}}
+          add_header {{$key}} "{{$value}}";{{end}}
+       }
+    }
+}
+`,
+
+	DoozerDockerfile: `FROM {{.BaseImage}}
+
+MAINTAINER {{.Maintainer}}
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+# temp env hack until standard base image is available
+ENV LANG='en_US.UTF-8' \
+    TZ=Europe/Oslo \
+    HOME=/u01
+
+COPY ./app radish.json $HOME/
+COPY ./app/application/{{.SrcPath}}{{.FileName}} {{.DestPath}}
+
+RUN find $HOME/application -type d -exec chmod 755 {} + && \
+	find $HOME/application -type f -exec chmod 644 {} + && \
+	mkdir -p $HOME/logs && \
+	chmod 777 $HOME/logs && \
+	ln -s $HOME/logs $HOME/application/logs
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+`,
+
+	DoozerDockerfileCmd: `CMD "{{.CmdScript}}"
+`,
+}
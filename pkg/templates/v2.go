@@ -0,0 +1,206 @@
+package templates
+
+// Version 2 adds the Java layers, CA certificates, readiness HEALTHCHECK, docker.extensions, doozer copies and runtime
+// instructions, python, and the web applications, proxies, precompressed content and fingerprinted cache strategy of
+// nodejs. Base images get it with the template version label set to 2
+var version2 = map[string]string{
+	JavaDockerfile: `FROM {{.BaseImage}}
+
+MAINTAINER {{.Maintainer}}
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+{{range .Layers}}COPY ./layers/{{.}} $HOME/
+{{end}}COPY radish.json $HOME/
+{{if .CaCertificates}}COPY ./security $HOME/security/
+{{end}}{{if .MergeCaCertificates}}RUN sh $HOME/security/merge-ca-certificates.sh
+{{end}}RUN mkdir -p $HOME/logs && \
+	chmod 777 $HOME/logs && \
+	ln -s $HOME/logs $HOME/application/logs
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+{{if .Extensions}}{{.Extensions}}
+{{end}}{{if .HealthCheck}}{{.HealthCheck}}
+{{end}}`,
+
+	JavaTestImageDockerfile: `FROM {{.BaseImage}}
+
+MAINTAINER {{.Maintainer}}
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+{{range .Layers}}COPY ./layers/{{.}} $HOME/
+{{end}}COPY radish.json $HOME/
+{{if .CaCertificates}}COPY ./security $HOME/security/
+{{end}}{{if .MergeCaCertificates}}RUN sh $HOME/security/merge-ca-certificates.sh
+{{end}}RUN find $HOME/application -type d -exec chmod 777 {} + && \
+	mkdir -p $HOME/logs && \
+	chmod 777 $HOME/logs && \
+	ln -s $HOME/logs $HOME/application/logs
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+{{if .Extensions}}{{.Extensions}}
+{{end}}{{if .HealthCheck}}{{.HealthCheck}}
+{{end}}`,
+
+	NodejsDockerfile: `FROM {{.Baseimage}}
+
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+COPY ./{{.PackageDirectory}} /u01/application
+
+COPY ./overrides /u01/bin/
+
+COPY nginx-radish.json $HOME/
+
+COPY ./{{.PackageDirectory}}/{{.Static}} /u01/static{{.Path}}
+{{range .WebApps}}COPY ./{{$.PackageDirectory}}/{{.Content}} /u01/static{{.Path}}
+{{end}}
+RUN chmod 666 /etc/nginx/nginx.conf && \
+    chmod 777 /etc/nginx && \
+    chmod 755 /u01/bin/*
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+{{if .Extensions}}{{.Extensions}}
+{{end}}
+WORKDIR "/u01/"
+
+CMD ["/u01/bin/run_nginx"]`,
+
+	NodejsLegacyDockerfile: `FROM {{.Baseimage}}
+
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+COPY ./{{.PackageDirectory}} /u01/application
+
+COPY ./overrides /u01/bin/
+
+COPY ./{{.PackageDirectory}}/{{.Static}} /u01/static{{.Path}}
+{{range .WebApps}}COPY ./{{$.PackageDirectory}}/{{.Content}} /u01/static{{.Path}}
+{{end}}
+COPY nginx.conf /etc/nginx/nginx.conf
+
+RUN chmod 666 /etc/nginx/nginx.conf && \
+    chmod 777 /etc/nginx && \
+    chmod 755 /u01/bin/*
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+{{if .Extensions}}{{.Extensions}}
+{{end}}
+WORKDIR "/u01/"
+
+CMD ["/u01/bin/run_nginx"]`,
+
+	NodejsLegacyNginxConf: `
+worker_processes  1;
+error_log stderr;
+
+events {
+    worker_connections  1024;
+}
+
+
+http {
+    include       /etc/nginx/mime.types;
+    default_type  application/octet-stream;
+
+    log_format  main  '$remote_addr - $remote_user [$time_local] "$request" '
+                      '$status $body_bytes_sent "$http_referer" '
+                      '"$http_user_agent" "$http_x_forwarded_for"';
+
+    access_log  /dev/stdout;
+
+    sendfile        on;
+    #tcp_nopush     on;
+
+    keepalive_timeout  65;
+
+    #gzip  on;
+
+    index index.html;
+
+    server {
+       listen 8080;{{range $key, $value := .ServerOverrides}}
+       {{$key}} {{$value}};{{end}}
+{{range .Proxies}}
+       location {{.Path}} {{"{"}}{{if .Rewrite}}
+          rewrite ^{{.Path}}(.*)$ {{.Rewrite}}$1 break;{{end}}
+          proxy_pass http://${{"{"}}{{.Upstream}}_HOST}:${{"{"}}{{.Upstream}}_PORT};{{if .Websocket}}
+          proxy_http_version 1.1;
+          proxy_set_header Upgrade $http_upgrade;
+          proxy_set_header Connection "upgrade";{{end}}{{range $key, $value := .Overrides}}
+          {{$key}} {{$value}};{{end}}
+       }
+{{end}}{{if .ApiLocation}}
+       location /api {
+          {{if or .HasNodeJSApplication .ConfigurableProxy}}proxy_pass http://${PROXY_PASS_HOST}:${PROXY_PASS_PORT};{{else}}return 404;{{end}}{{range $key, $value := .NginxOverrides}}
+          {{$key}} {{$value}};{{end}}
+       }
+{{end}}{{template "static" .}}{{range .WebApps}}
+{{template "static" .}}{{end}}
+    }
+}
+{{define "static"}}{{if .SPA}}
+       location {{.Path}} {
+          root /u01/static;
+          try_files $uri {{.Path}}index.html;{{else}}
+       location {{.Path}} {
+          root /u01/static;{{end}}{{if .Precompress}}
+          gzip_static on;
+          gzip_vary on;{{if .Brotli}}
+          brotli_static on;{{end}}{{end}}{{range $key, $value := .StaticOverrides}}
+          {{$key}} {{$value}};{{end}}{{range $key, $value := .ExtraStaticHeaders}}
+          add_header {{$key}} "{{$value}}";{{end}}{{if .FingerprintPattern}}
+
+          location ~ "{{.FingerprintPattern}}" {{"{"}}{{range $key, $value := .ImmutableHeaders}}
+             add_header {{$key}} "{{$value}}";{{end}}
+          }{{end}}
+       }{{end}}`,
+
+	DoozerDockerfile: `FROM {{.BaseImage}}
+
+MAINTAINER {{.Maintainer}}
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+ENV HOME={{.Home}}
+
+COPY ./app radish.json $HOME/
+{{range .Copies}}COPY {{if .Owner}}--chown={{.Owner}} {{end}}./app/application/{{.Source}} {{.Destination}}
+{{end}}
+RUN find $HOME/application -type d -exec chmod 755 {} + && \
+	find $HOME/application -type f -exec chmod 644 {} + && \
+	mkdir -p $HOME/logs && \
+	chmod 777 $HOME/logs && \
+	ln -s $HOME/logs $HOME/application/logs{{range .Copies}}{{if .Chmod}} && \
+	{{.Chmod}}{{end}}{{end}}
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+{{if .Extensions}}{{.Extensions}}
+{{end}}{{if .HealthCheck}}{{.HealthCheck}}
+{{end}}{{if .Runtime}}{{.Runtime}}
+{{end}}`,
+
+	DoozerDockerfileCmd: `CMD "{{.CmdScript}}"
+`,
+
+	PythonDockerfile: `FROM {{.BaseImage}}
+
+MAINTAINER {{.Maintainer}}
+LABEL{{range $key, $value := .Labels}} {{$key}}="{{$value}}"{{end}}
+
+ENV HOME={{.Home}}
+
+COPY ./app radish.json $HOME/
+
+RUN python3 -m venv {{.VirtualEnv}}{{if .Requirements}} && \
+	{{.VirtualEnv}}/bin/pip install --no-cache-dir{{if .IndexURL}} --index-url {{.IndexURL}}{{end}} -r $HOME/application/{{.Requirements}}{{end}}{{if .Wheel}} && \
+	{{.VirtualEnv}}/bin/pip install --no-cache-dir{{if .IndexURL}} --index-url {{.IndexURL}}{{end}} $HOME/application/{{.Wheel}}{{end}} && \
+	find $HOME/application -type d -exec chmod 755 {} + && \
+	find $HOME/application -type f -exec chmod 644 {} + && \
+	mkdir -p $HOME/logs && \
+	chmod 777 $HOME/logs && \
+	ln -s $HOME/logs $HOME/application/logs
+
+ENV{{range $key, $value := .Env}} {{$key}}="{{$value}}"{{end}}
+{{if .Extensions}}{{.Extensions}}
+{{end}}{{if .HealthCheck}}{{.HealthCheck}}
+{{end}}`,
+}
@@ -0,0 +1,131 @@
+package util
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractTgzDeliverable extracts a gzipped tarball into the application folder. Like for a zip, a single root folder
+// in the tarball is removed. Otherwise the content of the tarball is the application
+func ExtractTgzDeliverable(dockerBuildFolder string, deliverablePath string) error {
+	applicationRoot := filepath.Join(dockerBuildFolder, DockerfileApplicationFolder)
+	applicationFolder := filepath.Join(dockerBuildFolder, ApplicationBuildFolder)
+	if err := os.MkdirAll(applicationRoot, 0755); err != nil {
+		return errors.Wrap(err, "Failed to create application directory in Docker context")
+	}
+
+	extractedFolder, err := ioutil.TempDir(dockerBuildFolder, "extracted")
+	if err != nil {
+		return errors.Wrap(err, "Failed to create application directory in Docker context")
+	}
+	if err := extractTgz(deliverablePath, extractedFolder); err != nil {
+		return errors.Wrap(err, "Failed to extract application archive")
+	}
+
+	list, err := ioutil.ReadDir(extractedFolder)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to open application directory %s", extractedFolder)
+	} else if len(list) == 0 {
+		return errors.Errorf("Archive %s is empty", deliverablePath)
+	}
+
+	rootFolder := extractedFolder
+	if len(list) == 1 && list[0].IsDir() {
+		rootFolder = filepath.Join(extractedFolder, list[0].Name())
+	}
+	if err := os.Rename(rootFolder, applicationFolder); err != nil {
+		return errors.Wrapf(err, "Rename from %s to %s failed", rootFolder, applicationFolder)
+	}
+	return os.RemoveAll(extractedFolder)
+}
+
+func extractTgz(archivePath string, targetFolder string) error {
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to open archive %s", archivePath)
+	}
+	defer archive.Close()
+
+	gzipStream, err := gzip.NewReader(bufio.NewReader(archive))
+	if err != nil {
+		return errors.Wrapf(err, "Failed to read gzip stream in %s", archivePath)
+	}
+	defer gzipStream.Close()
+
+	tarReader := tar.NewReader(gzipStream)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "Failed to read archive %s", archivePath)
+		}
+
+		target := filepath.Join(targetFolder, header.Name)
+		if !insideFolder(targetFolder, target) {
+			return errors.Errorf("Illegal path %s in archive", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return errors.Wrapf(err, "Failed to extract directory %s", header.Name)
+			}
+		case tar.TypeReg:
+			if err := fillPathGap(target); err != nil {
+				return errors.Wrapf(err, "Failed to create directory tree for %s", header.Name)
+			}
+			if err := extractTarEntry(target, os.FileMode(header.Mode), tarReader); err != nil {
+				return errors.Wrapf(err, "Failed to extract file %s", header.Name)
+			}
+		case tar.TypeSymlink:
+			// The target of a symlink is relative to the folder of the link
+			if filepath.IsAbs(header.Linkname) || !insideFolder(targetFolder, filepath.Join(filepath.Dir(target), header.Linkname)) {
+				return errors.Errorf("Illegal symlink %s to %s in archive. The target must be in the archive", header.Name, header.Linkname)
+			}
+			if err := fillPathGap(target); err != nil {
+				return errors.Wrapf(err, "Failed to create directory tree for %s", header.Name)
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return errors.Wrapf(err, "Failed to extract symlink %s", header.Name)
+			}
+		case tar.TypeLink:
+			// The target of a hardlink is a path in the archive
+			linkTarget := filepath.Join(targetFolder, header.Linkname)
+			if !insideFolder(targetFolder, linkTarget) {
+				return errors.Errorf("Illegal hardlink %s to %s in archive. The target must be in the archive", header.Name, header.Linkname)
+			}
+			if err := fillPathGap(target); err != nil {
+				return errors.Wrapf(err, "Failed to create directory tree for %s", header.Name)
+			}
+			if err := os.Link(linkTarget, target); err != nil {
+				return errors.Wrapf(err, "Failed to extract hardlink %s", header.Name)
+			}
+		default:
+			logrus.Warnf("Skipping %s in archive. Only files, directories and links are supported", header.Name)
+		}
+	}
+}
+
+func insideFolder(folder string, path string) bool {
+	folder = filepath.Clean(folder)
+	return path == folder || strings.HasPrefix(path, folder+string(os.PathSeparator))
+}
+
+func extractTarEntry(target string, mode os.FileMode, reader io.Reader) error {
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(file, reader)
+	return err
+}
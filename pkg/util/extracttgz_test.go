@@ -0,0 +1,111 @@
+package util_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"github.com/skatteetaten/architect/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractTgzWithRootFolder(t *testing.T) {
+	buildFolder, archive := createTgz(t, map[string]string{
+		"app-1.0.0/bin/start": "#!/bin/sh",
+		"app-1.0.0/README":    "readme",
+	})
+	defer os.RemoveAll(buildFolder)
+
+	assert.NoError(t, util.ExtractTgzDeliverable(buildFolder, archive))
+	assertFileContent(t, filepath.Join(buildFolder, util.ApplicationBuildFolder, "bin", "start"), "#!/bin/sh")
+	assertFileContent(t, filepath.Join(buildFolder, util.ApplicationBuildFolder, "README"), "readme")
+}
+
+func TestExtractTgzWithoutRootFolder(t *testing.T) {
+	buildFolder, archive := createTgz(t, map[string]string{
+		"bin/start": "#!/bin/sh",
+		"README":    "readme",
+	})
+	defer os.RemoveAll(buildFolder)
+
+	assert.NoError(t, util.ExtractTgzDeliverable(buildFolder, archive))
+	assertFileContent(t, filepath.Join(buildFolder, util.ApplicationBuildFolder, "bin", "start"), "#!/bin/sh")
+	assertFileContent(t, filepath.Join(buildFolder, util.ApplicationBuildFolder, "README"), "readme")
+}
+
+func TestExtractTgzOutsideOfTheApplication(t *testing.T) {
+	buildFolder, archive := createTgz(t, map[string]string{
+		"../escaped": "content",
+	})
+	defer os.RemoveAll(buildFolder)
+
+	assert.Error(t, util.ExtractTgzDeliverable(buildFolder, archive))
+}
+
+func TestExtractTgzWithLinks(t *testing.T) {
+	buildFolder, archive := createTgzWithHeaders(t, []*tar.Header{
+		{Name: "app-1.0.0/lib/libfoo.so.1", Mode: 0644, Typeflag: tar.TypeReg},
+		{Name: "app-1.0.0/lib/libfoo.so", Linkname: "libfoo.so.1", Typeflag: tar.TypeSymlink},
+		{Name: "app-1.0.0/bin/libfoo.so", Linkname: "app-1.0.0/lib/libfoo.so.1", Typeflag: tar.TypeLink},
+	}, nil)
+	defer os.RemoveAll(buildFolder)
+
+	assert.NoError(t, util.ExtractTgzDeliverable(buildFolder, archive))
+	link, err := os.Readlink(filepath.Join(buildFolder, util.ApplicationBuildFolder, "lib", "libfoo.so"))
+	assert.NoError(t, err)
+	assert.Equal(t, "libfoo.so.1", link)
+	_, err = os.Stat(filepath.Join(buildFolder, util.ApplicationBuildFolder, "bin", "libfoo.so"))
+	assert.NoError(t, err)
+}
+
+func TestExtractTgzWithLinksOutsideOfTheApplication(t *testing.T) {
+	for expected, header := range map[string]*tar.Header{
+		"Illegal symlink passwd to /etc/passwd in archive. The target must be in the archive": {Name: "passwd", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink},
+		"Illegal symlink up to ../.. in archive. The target must be in the archive":           {Name: "up", Linkname: "../..", Typeflag: tar.TypeSymlink},
+		"Illegal hardlink shadow to ../shadow in archive. The target must be in the archive":  {Name: "shadow", Linkname: "../shadow", Typeflag: tar.TypeLink},
+	} {
+		buildFolder, archive := createTgzWithHeaders(t, []*tar.Header{header}, nil)
+		err := util.ExtractTgzDeliverable(buildFolder, archive)
+		os.RemoveAll(buildFolder)
+		assert.EqualError(t, err, "Failed to extract application archive: "+expected)
+	}
+}
+
+func createTgz(t *testing.T, files map[string]string) (string, string) {
+	headers := make([]*tar.Header, 0, len(files))
+	for name := range files {
+		headers = append(headers, &tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg})
+	}
+	return createTgzWithHeaders(t, headers, files)
+}
+
+// The files have the content of the regular files in the headers
+func createTgzWithHeaders(t *testing.T, headers []*tar.Header, files map[string]string) (string, string) {
+	buildFolder, err := ioutil.TempDir("", "extracttgz")
+	assert.NoError(t, err)
+	archive := filepath.Join(buildFolder, "deliverable.tgz")
+	file, err := os.Create(archive)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, header := range headers {
+		content := files[header.Name]
+		header.Size = int64(len(content))
+		assert.NoError(t, tarWriter.WriteHeader(header))
+		_, err := tarWriter.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+	return buildFolder, archive
+}
+
+func assertFileContent(t *testing.T, path string, expected string) {
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const (
@@ -144,6 +145,82 @@ func renameSingleFolderInDirectory(base string, newName string) error {
 	}
 }
 
+// DeliverableRootFolder returns the name of the single root folder in the deliverable, before it is renamed.
+// eg. myapplication-1.2.3 for myapplication-1.2.3-Leveransepakke.zip
+func DeliverableRootFolder(archivePath string) (string, error) {
+	zipReader, err := zip.OpenReader(archivePath)
+
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to open archive %s", archivePath)
+	}
+
+	defer zipReader.Close()
+
+	for _, zipEntry := range zipReader.File {
+		root := strings.Split(strings.TrimPrefix(zipEntry.Name, "/"), "/")[0]
+		if root != "" {
+			return root, nil
+		}
+	}
+	return "", errors.Errorf("Archive %s is empty", archivePath)
+}
+
+// DeliverableVersion returns the version in the name of the root folder of the deliverable, eg. 1.2.3 for
+// myapplication-1.2.3. Without the artifactId the version is assumed to start at the first digit after a dash
+func DeliverableVersion(rootFolder string, artifactId string) string {
+	if artifactId != "" {
+		if strings.HasPrefix(rootFolder, artifactId+"-") {
+			return strings.TrimPrefix(rootFolder, artifactId+"-")
+		}
+		return ""
+	}
+	for i := 1; i < len(rootFolder)-1; i++ {
+		if rootFolder[i] == '-' && rootFolder[i+1] >= '0' && rootFolder[i+1] <= '9' {
+			return rootFolder[i+1:]
+		}
+	}
+	return ""
+}
+
+// ReadFileInDeliverable reads a file below the root folder of the deliverable without extracting it,
+// eg. metadata/openshift.json
+func ReadFileInDeliverable(archivePath string, path string) ([]byte, error) {
+	content, found, err := FindFileInDeliverable(archivePath, path)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.Errorf("Could not find %s in archive %s", path, archivePath)
+	}
+	return content, nil
+}
+
+// FindFileInDeliverable is ReadFileInDeliverable for a file the deliverable may leave out
+func FindFileInDeliverable(archivePath string, path string) ([]byte, bool, error) {
+	zipReader, err := zip.OpenReader(archivePath)
+
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Failed to open archive %s", archivePath)
+	}
+
+	defer zipReader.Close()
+
+	for _, zipEntry := range zipReader.File {
+		parts := strings.SplitN(strings.TrimPrefix(zipEntry.Name, "/"), "/", 2)
+		if len(parts) != 2 || parts[1] != path {
+			continue
+		}
+		reader, err := zipEntry.Open()
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "Failed to open file %s", zipEntry.Name)
+		}
+		defer reader.Close()
+		content, err := ioutil.ReadAll(reader)
+		return content, err == nil, err
+	}
+	return nil, false, nil
+}
+
 func Exists(path string) (bool, error) {
 	_, err := os.Stat(path)
 
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+/*
+ReadProperties reads a Java properties file, like git.properties. It handles comments, the = and : separators,
+line continuations and the escaping of the separators, which is enough for generated files.
+*/
+func ReadProperties(reader io.Reader) (map[string]string, error) {
+	properties := make(map[string]string)
+	scanner := bufio.NewScanner(reader)
+	logicalLine := ""
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logicalLine == "" && (line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")) {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
+			logicalLine += strings.TrimSuffix(line, "\\")
+			continue
+		}
+		logicalLine += line
+
+		key, value := splitProperty(logicalLine)
+		properties[key] = value
+		logicalLine = ""
+	}
+	return properties, scanner.Err()
+}
+
+func splitProperty(line string) (string, string) {
+	key := new(strings.Builder)
+	escaped := false
+	for i, c := range line {
+		switch {
+		case escaped:
+			key.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '=' || c == ':' || c == ' ' || c == '\t':
+			value := strings.TrimLeft(line[i+1:], " \t")
+			if c == ' ' || c == '\t' {
+				value = strings.TrimLeft(strings.TrimPrefix(strings.TrimPrefix(value, "="), ":"), " \t")
+			}
+			return key.String(), unescapeProperty(value)
+		default:
+			key.WriteRune(c)
+		}
+	}
+	return key.String(), ""
+}
+
+func unescapeProperty(value string) string {
+	replacer := strings.NewReplacer("\\:", ":", "\\=", "=", "\\\\", "\\", "\\n", "\n", "\\t", "\t", "\\ ", " ", "\\#", "#", "\\!", "!")
+	return replacer.Replace(value)
+}
@@ -0,0 +1,34 @@
+package util_test
+
+import (
+	"github.com/skatteetaten/architect/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestReadProperties(t *testing.T) {
+	properties, err := util.ReadProperties(strings.NewReader(`# comment
+! also a comment
+git.branch=master
+git.build.time = 2018-09-10T14\:30\:10+0200
+git.commit.message.full=First line\nsecond line
+git.remote.origin.url:https\://git.aurora.skead.no/scm/aurora/minarch.git
+git.tags   v1.2.22
+git.closest.tag.name=v1.2.\
+    22
+key\=with\:separators=value
+empty=
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"git.branch":              "master",
+		"git.build.time":          "2018-09-10T14:30:10+0200",
+		"git.commit.message.full": "First line\nsecond line",
+		"git.remote.origin.url":   "https://git.aurora.skead.no/scm/aurora/minarch.git",
+		"git.tags":                "v1.2.22",
+		"git.closest.tag.name":    "v1.2.22",
+		"key=with:separators":     "value",
+		"empty":                   "",
+	}, properties)
+}
@@ -74,3 +74,11 @@ func TestGetVersionOnly(t *testing.T) {
 	assert.Equal(t, "2.a.b", util.GetVersionWithoutMetadata("2.a.b"))
 	assert.Equal(t, "2.a.b", util.GetVersionWithoutMetadata("2.a.b+metadata"))
 }
+
+func TestDeliverableVersion(t *testing.T) {
+	assert.Equal(t, "1.2.22", util.DeliverableVersion("minarch-1.2.22", "minarch"))
+	assert.Equal(t, "", util.DeliverableVersion("other-1.2.22", "minarch"))
+	assert.Equal(t, "1.2.22", util.DeliverableVersion("my-app-1.2.22", ""))
+	assert.Equal(t, "feature-SNAPSHOT", util.DeliverableVersion("my-app-feature-SNAPSHOT", "my-app"))
+	assert.Equal(t, "", util.DeliverableVersion("application", ""))
+}